@@ -2,29 +2,55 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/digitaldrywood/timetracker/internal/activity"
+	"github.com/digitaldrywood/timetracker/internal/calendar"
 	"github.com/digitaldrywood/timetracker/internal/config"
+	"github.com/digitaldrywood/timetracker/internal/daemon"
+	"github.com/digitaldrywood/timetracker/internal/database"
 	"github.com/digitaldrywood/timetracker/internal/github"
+	"github.com/digitaldrywood/timetracker/internal/gitlab"
 	"github.com/digitaldrywood/timetracker/internal/google"
+	"github.com/digitaldrywood/timetracker/internal/rules"
 	"github.com/digitaldrywood/timetracker/internal/tracker"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "daemon" {
+		runDaemon()
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "rules" {
+		runRules(os.Args[2:])
+		return
+	}
+
 	var (
 		summary = flag.Bool("summary", false, "Show daily summary")
 		week    = flag.Bool("week", false, "Show weekly summary")
 		add     = flag.Bool("add", false, "Add time entry interactively")
 		suggest = flag.Bool("suggest", false, "Generate suggested entries from GitHub activity")
+		date    = flag.String("date", "today", "Day or range to summarize, suggest or add entries for: YYYY-MM-DD, YYYY-MM-DD..YYYY-MM-DD, today or yesterday")
 	)
 	flag.Parse()
 
+	first, last, err := tracker.ParseDateRange(*date, time.Now())
+	if err != nil {
+		log.Fatalf("Invalid -date: %v", err)
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
@@ -42,29 +68,443 @@ func main() {
 
 	sheets := google.NewSheetsClient(service, cfg.SpreadsheetID)
 
-	gh, err := github.NewClient()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	db, err := database.New(cfg.DataDir)
 	if err != nil {
-		log.Fatalf("Failed to create GitHub client: %v", err)
+		log.Fatalf("Failed to open database: %v", err)
 	}
+	defer db.Close()
 
-	t := tracker.NewTracker(sheets, gh)
+	sources, err := buildSources(cfg, db)
+	if err != nil {
+		log.Fatalf("Failed to create activity sources: %v", err)
+	}
+
+	ruleset, err := rules.Load(cfg.RulesPath)
+	if err != nil {
+		log.Fatalf("Failed to load rules: %v", err)
+	}
+
+	history, err := learnHistory(cfg.Learn, sheets, db)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	t, err := tracker.NewTracker(sheets, sources, tracker.Options{
+		IssuePatterns: cfg.Issues.Patterns,
+		IssueColumn:   cfg.Issues.Column,
+		TaskTypes:     cfg.TaskTypes,
+		Rules:         ruleset,
+		History:       history,
+		HistoryWeeks:  cfg.Learn.Weeks,
+
+		DescriptionTemplates: cfg.DescriptionTemplates,
+	})
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 
 	switch {
 	case *summary:
-		showDailySummary(t)
+		showDailySummary(ctx, t, first, last)
 	case *week:
 		showWeeklySummary(t)
 	case *add:
-		addTimeEntry(t)
+		addTimeEntry(t, db, first, last)
 	case *suggest:
-		suggestEntries(t)
+		suggestEntries(ctx, t, db, first, last)
 	default:
-		showDailySummary(t)
+		showDailySummary(ctx, t, first, last)
+	}
+}
+
+// buildSources creates the activity sources listed in the config file, or
+// the default GitHub, GitLab and local git sources.
+func buildSources(cfg *config.Config, db *database.DB) ([]activity.Source, error) {
+	identity := github.Identity{Names: cfg.Identity.Names, Emails: cfg.Identity.Emails}
+
+	registry := activity.NewRegistry()
+	registry.Register("github", func(settings json.RawMessage) (activity.Source, error) {
+		var host config.GitHubHost
+		if err := decodeSettings(settings, &host); err != nil {
+			return nil, err
+		}
+		gh, err := github.New(github.Options{
+			Host:       host.Host,
+			BaseURL:    host.APIURL,
+			GraphQLURL: host.GraphQLURL,
+			TokenEnv:   host.TokenEnv,
+			Include:    cfg.GitHub.Include,
+			Exclude:    cfg.GitHub.Exclude,
+			Identity:   identity,
+			Cache:      db,
+			Progress:   printProgress,
+			Warn:       printWarning,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return github.NewSource(gh), nil
+	})
+	registry.Register("gitlab", func(settings json.RawMessage) (activity.Source, error) {
+		var instance config.GitLabInstance
+		if err := decodeSettings(settings, &instance); err != nil {
+			return nil, err
+		}
+		gl, err := gitlab.New(gitlab.Options{
+			BaseURL:  instance.BaseURL,
+			TokenEnv: instance.TokenEnv,
+			Identity: identity,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return gitlab.NewSource(gl), nil
+	})
+	// The local and reflog sources usually share their settings, and then
+	// one collector, so the roots are only searched once
+	collectors := make(map[string]*github.LocalCollector)
+	sharedCollector := func(settings json.RawMessage) (*github.LocalCollector, error) {
+		key := string(settings)
+		if local, ok := collectors[key]; ok {
+			return local, nil
+		}
+		local, err := newLocalCollector(settings, identity, db)
+		if err != nil {
+			return nil, err
+		}
+		collectors[key] = local
+		return local, nil
+	}
+	registry.Register("local", func(settings json.RawMessage) (activity.Source, error) {
+		local, err := sharedCollector(settings)
+		if err != nil {
+			return nil, err
+		}
+		return local, nil
+	})
+	registry.Register("reflog", func(settings json.RawMessage) (activity.Source, error) {
+		local, err := sharedCollector(settings)
+		if err != nil {
+			return nil, err
+		}
+		return github.NewReflogSource(local), nil
+	})
+	registry.Register("heartbeat", func(settings json.RawMessage) (activity.Source, error) {
+		var heartbeat config.HeartbeatSource
+		if err := decodeSettings(settings, &heartbeat); err != nil {
+			return nil, err
+		}
+		idle, err := parseDuration("idle_timeout", heartbeat.IdleTimeout)
+		if err != nil {
+			return nil, err
+		}
+		return daemon.NewSource(db, idle), nil
+	})
+	registry.Register("calendar", func(settings json.RawMessage) (activity.Source, error) {
+		var cal config.CalendarSource
+		if err := decodeSettings(settings, &cal); err != nil {
+			return nil, err
+		}
+		if len(cal.Paths) == 0 {
+			return nil, fmt.Errorf("calendar source needs paths")
+		}
+
+		opts := calendar.Options{Paths: cal.Paths, Emails: cal.Emails, Warn: printWarning}
+		if len(opts.Emails) == 0 {
+			opts.Emails = cfg.Identity.Emails
+		}
+		for _, client := range cal.Clients {
+			opts.Rules = append(opts.Rules, calendar.Rule{
+				Client:   client.Client,
+				Domains:  client.Domains,
+				Keywords: client.Keywords,
+			})
+		}
+		return calendar.NewSource(opts), nil
+	})
+
+	specs, err := cfg.ActivitySources()
+	if err != nil {
+		return nil, err
+	}
+
+	sources, err := registry.Build(specs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Local commits may be authored under any of the hosts' logins
+	var logins []string
+	for _, source := range sources {
+		if user, ok := source.(interface{ Username() string }); ok {
+			logins = append(logins, user.Username())
+		}
+	}
+	for _, local := range collectors {
+		local.AddLogins(logins...)
+	}
+
+	return sources, nil
+}
+
+// newLocalCollector builds a local clone scanner from "local" or "reflog"
+// source settings.
+func newLocalCollector(settings json.RawMessage, identity github.Identity, db *database.DB) (*github.LocalCollector, error) {
+	var local config.LocalSource
+	if err := decodeSettings(settings, &local); err != nil {
+		return nil, err
+	}
+	return localCollector(local, identity, db)
+}
+
+func localCollector(local config.LocalSource, identity github.Identity, db *database.DB) (*github.LocalCollector, error) {
+	rescan, err := parseDuration("rescan_interval", local.RescanInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	return github.NewLocalCollector(identity, github.LocalOptions{
+		Roots:          local.Roots,
+		Exclude:        local.Exclude,
+		MaxDepth:       local.MaxDepth,
+		RescanInterval: rescan,
+		Cache:          db,
+	}), nil
+}
+
+// learnHistory returns where suggestions learn from; nil turns learning
+// off.
+func learnHistory(learn config.LearnConfig, sheets *google.SheetsClient, db *database.DB) (tracker.History, error) {
+	switch learn.Source {
+	case "", "database":
+		return databaseHistory{db: db}, nil
+	case "sheets":
+		return tracker.SheetsHistory(sheets), nil
+	case "off":
+		return nil, nil
+	}
+	return nil, fmt.Errorf("invalid learn source %q: must be \"database\", \"sheets\" or \"off\"", learn.Source)
+}
+
+// databaseHistory learns from the entries recorded in the database, which
+// remember the repository each suggestion was built for.
+type databaseHistory struct {
+	db *database.DB
+}
+
+func (h databaseHistory) PastEntries(from, to time.Time) ([]tracker.PastEntry, error) {
+	entries, err := h.db.GetPastEntries(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	past := make([]tracker.PastEntry, len(entries))
+	for i, entry := range entries {
+		past[i] = tracker.PastEntry(entry)
+	}
+	return past, nil
+}
+
+// parseDuration parses an optional duration setting; empty means the
+// default, zero.
+func parseDuration(name, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %v", name, err)
+	}
+	return d, nil
+}
+
+// runDaemon records file activity in the local clones until interrupted.
+// It doesn't need Google credentials, only the database.
+func runDaemon() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	db, err := database.New(cfg.DataDir)
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	identity := github.Identity{Names: cfg.Identity.Names, Emails: cfg.Identity.Emails}
+	local, err := localCollector(cfg.Local, identity, db)
+	if err != nil {
+		log.Fatalf("Invalid local settings: %v", err)
+	}
+
+	interval, err := parseDuration("poll_interval", cfg.Daemon.PollInterval)
+	if err != nil {
+		log.Fatalf("Invalid daemon settings: %v", err)
+	}
+	retention, err := parseDuration("retention", cfg.Daemon.Retention)
+	if err != nil {
+		log.Fatalf("Invalid daemon settings: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err = daemon.Run(ctx, daemon.Options{
+		Local:        local,
+		Store:        db,
+		Ignore:       cfg.Daemon.Ignore,
+		Polling:      cfg.Daemon.Polling,
+		PollInterval: interval,
+		Retention:    retention,
+		Logf:         log.Printf,
+	})
+	if err != nil {
+		log.Fatalf("Daemon failed: %v", err)
 	}
 }
 
-func showDailySummary(t *tracker.Tracker) {
-	summary, err := t.GetDailySummary()
+// runRules handles `timetracker rules test`, which shows how the rules file
+// classifies an example item described by flags, or the activity for a day
+// or range.
+func runRules(args []string) {
+	if len(args) == 0 || args[0] != "test" {
+		log.Fatalf("Usage: timetracker rules test [-date DATE] [-repo REPO] [-path PATH] [-message MESSAGE] [-branch BRANCH] [-time \"YYYY-MM-DD HH:MM\"]")
+	}
+
+	flags := flag.NewFlagSet("rules test", flag.ExitOnError)
+	var (
+		date    = flags.String("date", "today", "Day or range whose activity to classify")
+		repo    = flags.String("repo", "", "Repository of an example item")
+		path    = flags.String("path", "", "Local working tree of an example item")
+		message = flags.String("message", "", "Commit message of an example item")
+		branch  = flags.String("branch", "", "Branch of an example item")
+		at      = flags.String("time", "", "Local time of an example item, YYYY-MM-DD HH:MM (default now)")
+	)
+	flags.Parse(args[1:])
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	ruleset, err := rules.Load(cfg.RulesPath)
+	if err != nil {
+		log.Fatalf("Failed to load rules: %v", err)
+	}
+	fmt.Printf("%d rules from %s\n\n", len(ruleset.Rules), cfg.RulesPath)
+
+	var items []activity.Item
+	if *repo != "" || *path != "" || *message != "" || *branch != "" || *at != "" {
+		item := activity.Item{
+			Kind:       activity.KindCommit,
+			Repository: *repo,
+			Path:       *path,
+			Title:      strings.Split(*message, "\n")[0],
+			Body:       *message,
+			Branch:     *branch,
+			Time:       time.Now(),
+		}
+		if *at != "" {
+			if item.Time, err = time.ParseInLocation("2006-01-02 15:04", *at, time.Local); err != nil {
+				log.Fatalf("Invalid -time: %v", err)
+			}
+		}
+		items = append(items, item)
+	} else {
+		first, last, err := tracker.ParseDateRange(*date, time.Now())
+		if err != nil {
+			log.Fatalf("Invalid -date: %v", err)
+		}
+
+		db, err := database.New(cfg.DataDir)
+		if err != nil {
+			log.Fatalf("Failed to open database: %v", err)
+		}
+		defer db.Close()
+
+		sources, err := buildSources(cfg, db)
+		if err != nil {
+			log.Fatalf("Failed to create activity sources: %v", err)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		from := first
+		to := last.AddDate(0, 0, 1)
+		if items, err = activity.FetchAll(ctx, sources, from, to); err != nil {
+			log.Fatalf("Failed to get activity: %v", err)
+		}
+	}
+
+	for _, item := range items {
+		fmt.Printf("%s %s %s: %s\n", item.Time.Local().Format("2006-01-02 15:04"), item.Kind, item.Repository, item.Title)
+
+		matching := ruleset.Matching(item)
+		if len(matching) == 0 {
+			fmt.Print("  no rules match\n\n")
+			continue
+		}
+		for _, rule := range matching {
+			fmt.Printf("  matches %s\n", rule.Name)
+		}
+
+		classified := ruleset.Classify(item)
+		var set []string
+		for _, field := range []struct{ name, value string }{
+			{"client", classified.Client},
+			{"project", classified.Project},
+			{"task", classified.Task},
+			{"description", classified.Description},
+		} {
+			if field.value != "" {
+				set = append(set, fmt.Sprintf("%s=%q", field.name, field.value))
+			}
+		}
+		if classified.Billable != nil {
+			set = append(set, fmt.Sprintf("billable=%t", *classified.Billable))
+		}
+		fmt.Printf("  => %s\n\n", strings.Join(set, " "))
+	}
+}
+
+// decodeSettings parses a source's settings; sources without any use
+// their defaults.
+func decodeSettings(settings json.RawMessage, out interface{}) error {
+	if len(settings) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(settings, out); err != nil {
+		return fmt.Errorf("invalid settings: %v", err)
+	}
+	return nil
+}
+
+// printProgress reports GitHub fetch progress on stderr so it doesn't mix
+// with the summary on stdout.
+func printProgress(p github.Progress) {
+	if p.Wait > 0 {
+		fmt.Fprintf(os.Stderr, "\r\033[K⏳ %s %s, waiting %s", p.Host, p.Stage, p.Wait.Round(time.Second))
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "\r\033[K🔍 Fetching %s from %s: %d/%d", p.Stage, p.Host, p.Done, p.Total)
+	if p.Done == p.Total {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+// printWarning reports a problem that doesn't stop the summary on stderr.
+func printWarning(err error) {
+	fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+}
+
+func showDailySummary(ctx context.Context, t *tracker.Tracker, first, last time.Time) {
+	summary, err := t.GetSummary(ctx, first, last)
 	if err != nil {
 		log.Fatalf("Failed to get daily summary: %v", err)
 	}
@@ -87,10 +527,54 @@ func showWeeklySummary(t *tracker.Tracker) {
 	fmt.Printf("\nTotal: %.1f hours\n", total)
 }
 
-func addTimeEntry(t *tracker.Tracker) {
+// logTimeEntry adds an entry to the sheet and records it, with its task
+// type, in the database. Suggestions carry their task type; otherwise it's
+// taken from the task, without any issue keys in front of it.
+func logTimeEntry(t *tracker.Tracker, db *database.DB, entry google.TimeEntry) error {
+	if err := t.AddTimeEntry(entry); err != nil {
+		return err
+	}
+
+	if entry.TaskType == "" {
+		entry.TaskType = t.TaskTypeOf(entry.Task)
+	}
+	err := db.RecordTimeEntry(entry.Project, entry.Client, &database.TimeEntry{
+		Date:        entry.Date,
+		Hours:       entry.Hours,
+		Description: sql.NullString{String: entry.Description, Valid: entry.Description != ""},
+		TaskType:    sql.NullString{String: entry.TaskType, Valid: entry.TaskType != ""},
+		Billable:    entry.Billable,
+		Task:        sql.NullString{String: entry.Task, Valid: entry.Task != ""},
+		Repository:  sql.NullString{String: entry.Repository, Valid: entry.Repository != ""},
+		Activity:    tracker.Activity(entry),
+	})
+	if err != nil {
+		// The sheet is the record; the database copy is for reporting and learning
+		log.Printf("Failed to record entry in the database: %v", err)
+	}
+	return nil
+}
+
+func addTimeEntry(t *tracker.Tracker, db *database.DB, first, last time.Time) {
 	reader := bufio.NewReader(os.Stdin)
 
-	entry := google.TimeEntry{}
+	entry := google.TimeEntry{Date: first.Format("2006-01-02"), Billable: true}
+
+	// A range asks which of its days the entry is for
+	if last.After(first) {
+		fmt.Printf("Date (%s to %s, Enter for %s): ", entry.Date, last.Format("2006-01-02"), entry.Date)
+		input, _ := reader.ReadString('\n')
+		if input = strings.TrimSpace(input); input != "" {
+			day, _, err := tracker.ParseDateRange(input, time.Now())
+			if err != nil {
+				log.Fatalf("Invalid date: %v", err)
+			}
+			if day.Before(first) || day.After(last) {
+				log.Fatalf("Date %s is outside %s to %s", input, entry.Date, last.Format("2006-01-02"))
+			}
+			entry.Date = day.Format("2006-01-02")
+		}
+	}
 
 	fmt.Print("Project: ")
 	entry.Project, _ = reader.ReadString('\n')
@@ -99,6 +583,7 @@ func addTimeEntry(t *tracker.Tracker) {
 	fmt.Print("Task: ")
 	entry.Task, _ = reader.ReadString('\n')
 	entry.Task = strings.TrimSpace(entry.Task)
+	entry.TaskType = t.TaskTypeOf(entry.Task)
 
 	fmt.Print("Hours: ")
 	hoursStr, _ := reader.ReadString('\n')
@@ -109,48 +594,62 @@ func addTimeEntry(t *tracker.Tracker) {
 	entry.Description, _ = reader.ReadString('\n')
 	entry.Description = strings.TrimSpace(entry.Description)
 
-	entry.Date = time.Now().Format("2006-01-02")
-
-	if err := t.AddTimeEntry(entry); err != nil {
+	if err := logTimeEntry(t, db, entry); err != nil {
 		log.Fatalf("Failed to add time entry: %v", err)
 	}
 
 	fmt.Println("Time entry added successfully!")
 }
 
-func suggestEntries(t *tracker.Tracker) {
-	summary, err := t.GetDailySummary()
+func suggestEntries(ctx context.Context, t *tracker.Tracker, db *database.DB, first, last time.Time) {
+	summary, err := t.GetSummary(ctx, first, last)
 	if err != nil {
 		log.Fatalf("Failed to get daily summary: %v", err)
 	}
 
 	if len(summary.SuggestedEntries) == 0 {
-		fmt.Println("No suggested entries based on today's GitHub activity.")
+		fmt.Printf("No suggested entries based on activity for %s.\n", summary.Date)
 		return
 	}
 
 	fmt.Println(t.FormatDailySummary(summary))
 
 	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("\nWould you like to add any of these entries? (y/n): ")
+	fmt.Print("\nWould you like to add any of these entries? (y/n, or a to add them all as suggested): ")
 	response, _ := reader.ReadString('\n')
 
-	if strings.ToLower(strings.TrimSpace(response)) != "y" {
+	switch strings.ToLower(strings.TrimSpace(response)) {
+	case "y":
+	case "a":
+		addAllEntries(t, db, summary.SuggestedEntries)
+		return
+	default:
 		return
 	}
 
 	for i, entry := range summary.SuggestedEntries {
 		fmt.Printf("\n--- Entry %d ---\n", i+1)
+		fmt.Printf("Date: %s\n", entry.Date)
 		fmt.Printf("Project: %s\n", entry.Project)
 		fmt.Printf("Task: %s\n", entry.Task)
+		if entry.Confidence > 0 {
+			fmt.Printf("Learned from past entries: %.0f%% confidence\n", entry.Confidence*100)
+		}
 
-		fmt.Print("Hours worked (or 'skip'): ")
+		if entry.Hours > 0 {
+			fmt.Printf("Hours worked (Enter for %.2f, or 'skip'): ", entry.Hours)
+		} else {
+			fmt.Print("Hours worked (or 'skip'): ")
+		}
 		input, _ := reader.ReadString('\n')
 		input = strings.TrimSpace(input)
 
 		if input == "skip" {
 			continue
 		}
+		if input == "" && entry.Hours > 0 {
+			input = strconv.FormatFloat(entry.Hours, 'f', -1, 64)
+		}
 
 		hours, err := strconv.ParseFloat(input, 64)
 		if err != nil {
@@ -162,12 +661,35 @@ func suggestEntries(t *tracker.Tracker) {
 
 		fmt.Print("Additional description (optional): ")
 		desc, _ := reader.ReadString('\n')
-		entry.Description = strings.TrimSpace(desc)
+		if desc = strings.TrimSpace(desc); desc != "" {
+			if entry.Description != "" {
+				desc = entry.Description + "\n" + desc
+			}
+			entry.Description = desc
+		}
 
-		if err := t.AddTimeEntry(entry); err != nil {
+		if err := logTimeEntry(t, db, entry); err != nil {
 			fmt.Printf("Failed to add entry: %v\n", err)
 		} else {
 			fmt.Println("Entry added successfully!")
 		}
 	}
 }
+
+// addAllEntries logs every suggestion as it is. Suggestions without an
+// estimate need their hours entered and are left out.
+func addAllEntries(t *tracker.Tracker, db *database.DB, entries []google.TimeEntry) {
+	added := 0
+	for i, entry := range entries {
+		if entry.Hours <= 0 {
+			fmt.Printf("Entry %d (%s - %s) has no estimated hours, skipping...\n", i+1, entry.Project, entry.Task)
+			continue
+		}
+		if err := logTimeEntry(t, db, entry); err != nil {
+			fmt.Printf("Failed to add entry %d: %v\n", i+1, err)
+			continue
+		}
+		added++
+	}
+	fmt.Printf("Added %d of %d entries.\n", added, len(entries))
+}
@@ -0,0 +1,145 @@
+package activity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+type Kind string
+
+const (
+	KindCommit      Kind = "commit"
+	KindPullRequest Kind = "pull_request"
+	KindReview      Kind = "review"
+	KindIssue       Kind = "issue"
+
+	// KindWorkInProgress is uncommitted work in a local clone: changed
+	// files (State "changes") or a stash (State "stash"), spanning Time
+	// to End.
+	KindWorkInProgress Kind = "work_in_progress"
+
+	// KindSession is a stretch of continuous local work from Time to End;
+	// Number counts the branch switches during it.
+	KindSession Kind = "session"
+
+	// KindMeeting is a calendar event from Time to End. Repository is the
+	// client it was assigned to and State the user's reply to it.
+	KindMeeting Kind = "meeting"
+)
+
+// Item is one timestamped piece of work reported by a Source.
+type Item struct {
+	Source     string // Name() of the source that reported it
+	Kind       Kind
+	Repository string // host/owner/repo, or another stable project identity
+	Time       time.Time
+	End        time.Time // zero for point-in-time activity
+
+	Title  string // commit subject, PR/issue title
+	Body   string // full commit message, review or comment body
+	URL    string
+	SHA    string
+	Branch string
+	Number int    // pull request, merge request or issue number
+	State  string // PR state, review state or issue action
+	Path   string // working tree of a local clone the work was found in
+	Local  bool   // found in a local clone rather than through an API
+	Pushed bool   // commit is on a remote; always true for API sources
+
+	// Diff stats for commits
+	Additions    int
+	Deletions    int
+	FilesChanged int
+
+	// Classification set by rules; empty fields keep the defaults.
+	// Description is a template for the description of the entry the
+	// item ends up in.
+	Client      string
+	Project     string
+	Task        string
+	Billable    *bool
+	Description string
+}
+
+// Source reports activity for a time range. Implementations should return
+// items whose Time falls in [from, to).
+type Source interface {
+	Name() string
+	Fetch(ctx context.Context, from, to time.Time) ([]Item, error)
+}
+
+// Spec is one entry of the "sources" list in the config file.
+type Spec struct {
+	Type     string          `json:"type"`
+	Settings json.RawMessage `json:"settings"`
+}
+
+// Factory builds a Source from its settings.
+type Factory func(settings json.RawMessage) (Source, error)
+
+// Registry maps source types to the factories that build them.
+type Registry struct {
+	factories map[string]Factory
+}
+
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+// Register adds the factory for a source type. Registering a type twice is
+// a programming error and panics.
+func (r *Registry) Register(sourceType string, factory Factory) {
+	if _, exists := r.factories[sourceType]; exists {
+		panic(fmt.Sprintf("activity: source type %q registered twice", sourceType))
+	}
+	r.factories[sourceType] = factory
+}
+
+// Build creates a Source for every spec, in order.
+func (r *Registry) Build(specs []Spec) ([]Source, error) {
+	var sources []Source
+	for _, spec := range specs {
+		factory, ok := r.factories[spec.Type]
+		if !ok {
+			return nil, fmt.Errorf("unknown activity source type %q", spec.Type)
+		}
+
+		source, err := factory(spec.Settings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s source: %v", spec.Type, err)
+		}
+		sources = append(sources, source)
+	}
+
+	return sources, nil
+}
+
+// FetchAll collects items from every source and returns them oldest first.
+func FetchAll(ctx context.Context, sources []Source, from, to time.Time) ([]Item, error) {
+	var items []Item
+	for _, source := range sources {
+		fetched, err := source.Fetch(ctx, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", source.Name(), err)
+		}
+		items = append(items, fetched...)
+	}
+
+	items = Merge(items)
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Time.Before(items[j].Time) })
+	return items, nil
+}
+
+// Filter returns the items of the given kind.
+func Filter(items []Item, kind Kind) []Item {
+	var filtered []Item
+	for _, item := range items {
+		if item.Kind == kind {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
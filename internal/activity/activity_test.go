@@ -0,0 +1,140 @@
+package activity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubSource returns fixed items, or an error.
+type stubSource struct {
+	name  string
+	items []Item
+	err   error
+
+	from, to time.Time // the range it was asked for
+}
+
+func (s *stubSource) Name() string {
+	return s.name
+}
+
+func (s *stubSource) Fetch(ctx context.Context, from, to time.Time) ([]Item, error) {
+	s.from, s.to = from, to
+	return s.items, s.err
+}
+
+func TestRegistryBuild(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("stub", func(settings json.RawMessage) (Source, error) {
+		var opts struct {
+			Name string `json:"name"`
+		}
+		if len(settings) > 0 {
+			if err := json.Unmarshal(settings, &opts); err != nil {
+				return nil, err
+			}
+		}
+		if opts.Name == "" {
+			opts.Name = "stub"
+		}
+		return &stubSource{name: opts.Name}, nil
+	})
+	registry.Register("broken", func(json.RawMessage) (Source, error) {
+		return nil, errors.New("missing token")
+	})
+
+	tests := []struct {
+		name      string
+		specs     []Spec
+		wantNames []string
+		wantErr   string
+	}{
+		{name: "no specs"},
+		{
+			name:      "in order, same type twice",
+			specs:     []Spec{{Type: "stub", Settings: json.RawMessage(`{"name":"work"}`)}, {Type: "stub"}},
+			wantNames: []string{"work", "stub"},
+		},
+		{name: "unknown type", specs: []Spec{{Type: "stub"}, {Type: "jira"}}, wantErr: `unknown activity source type "jira"`},
+		{name: "factory error", specs: []Spec{{Type: "broken"}}, wantErr: "failed to create broken source: missing token"},
+		{name: "invalid settings", specs: []Spec{{Type: "stub", Settings: json.RawMessage(`[]`)}}, wantErr: "failed to create stub source"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sources, err := registry.Build(tt.specs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Build: %v", err)
+			}
+
+			var names []string
+			for _, source := range sources {
+				names = append(names, source.Name())
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantNames, ",") {
+				t.Errorf("sources = %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestRegisterTwicePanics(t *testing.T) {
+	registry := NewRegistry()
+	factory := func(json.RawMessage) (Source, error) { return &stubSource{}, nil }
+	registry.Register("stub", factory)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a type twice didn't panic")
+		}
+	}()
+	registry.Register("stub", factory)
+}
+
+func TestFetchAll(t *testing.T) {
+	from := time.Date(2026, 1, 13, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+
+	calendar := &stubSource{name: "calendar", items: []Item{
+		{Kind: KindMeeting, Repository: "Acme", Title: "Standup", Time: from.Add(9 * time.Hour)},
+	}}
+	github := &stubSource{name: "github", items: []Item{
+		{Kind: KindCommit, Repository: "github.com/acme/website", SHA: "abc", Title: "Add search", Time: from.Add(11 * time.Hour)},
+		{Kind: KindPullRequest, Repository: "github.com/acme/website", Title: "Search", Time: from.Add(8 * time.Hour)},
+	}}
+
+	items, err := FetchAll(context.Background(), []Source{calendar, github}, from, to)
+	if err != nil {
+		t.Fatalf("FetchAll: %v", err)
+	}
+	if !calendar.from.Equal(from) || !github.to.Equal(to) {
+		t.Errorf("sources asked for %s to %s", calendar.from, github.to)
+	}
+
+	var titles []string
+	for _, item := range items {
+		titles = append(titles, item.Title)
+	}
+	if got, want := strings.Join(titles, ", "), "Search, Standup, Add search"; got != want {
+		t.Errorf("items = %s, want %s (oldest first)", got, want)
+	}
+	if kinds := Filter(items, KindCommit); len(kinds) != 1 || kinds[0].SHA != "abc" {
+		t.Errorf("Filter(commits) = %+v", kinds)
+	}
+
+	broken := &stubSource{name: "gitlab", err: errors.New("401 Unauthorized")}
+	_, err = FetchAll(context.Background(), []Source{calendar, broken}, from, to)
+	if err == nil || err.Error() != "gitlab: 401 Unauthorized" {
+		t.Errorf("err = %v, want it prefixed with the source", err)
+	}
+}
@@ -0,0 +1,63 @@
+package activity
+
+import "strings"
+
+// RepositoryKey normalizes a repository identity for comparison, so that
+// a clone's remote and an API name for the same repository match.
+func RepositoryKey(repository string) string {
+	key := strings.ToLower(strings.Trim(repository, "/"))
+	return strings.TrimSuffix(key, ".git")
+}
+
+// Merge collapses commits reported by more than one source, such as a
+// local clone and the GitHub API, into one item per repository and SHA.
+// The API record is kept, with the branch and local path filled in from the
+// other record if it lacks them. Repository names are also unified on the spelling the
+// APIs use, so each repository groups under a single name.
+func Merge(items []Item) []Item {
+	names := make(map[string]string)
+	for _, local := range []bool{false, true} {
+		for _, item := range items {
+			key := RepositoryKey(item.Repository)
+			if _, ok := names[key]; !ok && item.Local == local {
+				names[key] = item.Repository
+			}
+		}
+	}
+
+	var merged []Item
+	commits := make(map[string]int)
+	for _, item := range items {
+		item.Repository = names[RepositoryKey(item.Repository)]
+
+		if item.Kind != KindCommit || item.SHA == "" {
+			merged = append(merged, item)
+			continue
+		}
+
+		key := RepositoryKey(item.Repository) + "@" + strings.ToLower(item.SHA)
+		i, ok := commits[key]
+		if !ok {
+			commits[key] = len(merged)
+			merged = append(merged, item)
+			continue
+		}
+
+		existing := &merged[i]
+		if existing.Local && !item.Local {
+			item, *existing = *existing, item
+		}
+		existing.Pushed = existing.Pushed || item.Pushed
+		if existing.Branch == "" {
+			existing.Branch = item.Branch
+		}
+		if existing.FilesChanged == 0 {
+			existing.FilesChanged = item.FilesChanged
+		}
+		if existing.Path == "" {
+			existing.Path = item.Path
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,117 @@
+package activity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMerge(t *testing.T) {
+	at := time.Date(2026, 1, 13, 10, 0, 0, 0, time.UTC)
+	api := Item{
+		Source:     "github",
+		Kind:       KindCommit,
+		Repository: "github.com/Acme/website",
+		SHA:        "ABC123",
+		Title:      "Add search",
+		URL:        "https://github.com/Acme/website/commit/abc123",
+		Time:       at,
+		Pushed:     true,
+		Additions:  10,
+	}
+	local := Item{
+		Source:       "local",
+		Kind:         KindCommit,
+		Repository:   "github.com/acme/website.git",
+		SHA:          "abc123",
+		Title:        "Add search",
+		URL:          "file:///home/me/website/commit/abc123",
+		Time:         at,
+		Branch:       "feature/search",
+		Path:         "/home/me/website",
+		Local:        true,
+		FilesChanged: 3,
+	}
+
+	tests := []struct {
+		name  string
+		items []Item
+		want  []Item
+	}{
+		{
+			name:  "same SHA from both sources, API first",
+			items: []Item{api, local},
+			want: []Item{{
+				Source: "github", Kind: KindCommit, Repository: "github.com/Acme/website", SHA: "ABC123",
+				Title: "Add search", URL: api.URL, Time: at, Pushed: true, Additions: 10,
+				Branch: "feature/search", Path: "/home/me/website", FilesChanged: 3,
+			}},
+		},
+		{
+			name:  "same SHA from both sources, local first",
+			items: []Item{local, api},
+			want: []Item{{
+				Source: "github", Kind: KindCommit, Repository: "github.com/Acme/website", SHA: "ABC123",
+				Title: "Add search", URL: api.URL, Time: at, Pushed: true, Additions: 10,
+				Branch: "feature/search", Path: "/home/me/website", FilesChanged: 3,
+			}},
+		},
+		{
+			name:  "API fields win over local ones",
+			items: []Item{withBranch(local, "main"), withBranch(api, "release")},
+			want: []Item{{
+				Source: "github", Kind: KindCommit, Repository: "github.com/Acme/website", SHA: "ABC123",
+				Title: "Add search", URL: api.URL, Time: at, Pushed: true, Additions: 10,
+				Branch: "release", Path: "/home/me/website", FilesChanged: 3,
+			}},
+		},
+		{
+			name:  "local-only commit takes the API spelling of its repository",
+			items: []Item{api, withSHA(local, "def456")},
+			want: []Item{api, {
+				Source: "local", Kind: KindCommit, Repository: "github.com/Acme/website", SHA: "def456",
+				Title: "Add search", URL: local.URL, Time: at, Branch: "feature/search",
+				Path: "/home/me/website", Local: true, FilesChanged: 3,
+			}},
+		},
+		{
+			name:  "local-only repository keeps its name",
+			items: []Item{withSHA(local, "def456")},
+			want:  []Item{withSHA(local, "def456")},
+		},
+		{
+			name: "other kinds are never merged",
+			items: []Item{
+				{Kind: KindPullRequest, Repository: "github.com/acme/website", Number: 1},
+				{Kind: KindPullRequest, Repository: "github.com/acme/website", Number: 1},
+			},
+			want: []Item{
+				{Kind: KindPullRequest, Repository: "github.com/acme/website", Number: 1},
+				{Kind: KindPullRequest, Repository: "github.com/acme/website", Number: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Merge(tt.items)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Merge = %d items, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("item %d =\n%+v\nwant\n%+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func withBranch(item Item, branch string) Item {
+	item.Branch = branch
+	return item
+}
+
+func withSHA(item Item, sha string) Item {
+	item.SHA = sha
+	return item
+}
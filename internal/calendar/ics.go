@@ -0,0 +1,306 @@
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is a VEVENT from an iCalendar file. Recurring events keep their
+// RRULE; use occurrences to expand them.
+type Event struct {
+	UID          string
+	Summary      string
+	URL          string
+	Status       string // TENTATIVE, CONFIRMED or CANCELLED
+	Start        time.Time
+	End          time.Time
+	AllDay       bool
+	Organizer    string // email
+	Attendees    []Attendee
+	RRule        string
+	ExDates      []time.Time
+	RecurrenceID time.Time // set on an edited occurrence of a recurring event
+}
+
+// Attendee is an ATTENDEE of an event.
+type Attendee struct {
+	Email    string
+	Name     string
+	PartStat string // ACCEPTED, DECLINED, TENTATIVE, NEEDS-ACTION
+}
+
+// contentLine is one unfolded "NAME;PARAM=VALUE:value" line.
+type contentLine struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse reads the events of an iCalendar stream. Events that can't be read
+// are left out and, like time zones that aren't known, passed to warn if
+// it's set.
+func Parse(r io.Reader, warn func(error)) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+	if warn == nil {
+		warn = func(error) {}
+	}
+
+	var (
+		events  []Event
+		event   *Event
+		invalid error
+		nested  int // depth of components inside the event, e.g. VALARM
+		zones   = make(map[string]bool)
+	)
+	for _, raw := range lines {
+		line, ok := parseContentLine(raw)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case line.name == "BEGIN" && strings.EqualFold(line.value, "VEVENT"):
+			event = &Event{}
+			invalid = nil
+			nested = 0
+			continue
+		case event == nil:
+			continue
+		case line.name == "BEGIN":
+			nested++
+			continue
+		case line.name == "END" && nested > 0:
+			nested--
+			continue
+		case line.name == "END" && strings.EqualFold(line.value, "VEVENT"):
+			if invalid != nil {
+				warn(fmt.Errorf("skipped event %q: %v", event.Summary, invalid))
+			} else if !event.Start.IsZero() {
+				if event.End.IsZero() {
+					// Without DTEND or DURATION a date lasts a day and a
+					// date-time is instantaneous
+					event.End = event.Start
+					if event.AllDay {
+						event.End = event.Start.AddDate(0, 0, 1)
+					}
+				}
+				events = append(events, *event)
+			}
+			event = nil
+			continue
+		case nested > 0:
+			continue
+		}
+
+		if tzid := line.params["TZID"]; tzid != "" && !zones[tzid] {
+			zones[tzid] = true
+			if _, ok := location(tzid); !ok {
+				warn(fmt.Errorf("unknown time zone %q, using local time", tzid))
+			}
+		}
+		if err := event.set(line); err != nil && invalid == nil {
+			invalid = fmt.Errorf("invalid %s: %v", line.name, err)
+		}
+	}
+
+	return events, nil
+}
+
+func (e *Event) set(line contentLine) error {
+	var err error
+	switch line.name {
+	case "UID":
+		e.UID = line.value
+	case "SUMMARY":
+		e.Summary = unescape(line.value)
+	case "URL":
+		e.URL = line.value
+	case "STATUS":
+		e.Status = strings.ToUpper(line.value)
+	case "DTSTART":
+		e.Start, e.AllDay, err = parseTime(line)
+	case "DTEND":
+		e.End, _, err = parseTime(line)
+	case "DURATION":
+		var d time.Duration
+		if d, err = parseDuration(line.value); err == nil && !e.Start.IsZero() {
+			e.End = e.Start.Add(d)
+		}
+	case "ORGANIZER":
+		e.Organizer = mailto(line.value)
+	case "ATTENDEE":
+		e.Attendees = append(e.Attendees, Attendee{
+			Email:    mailto(line.value),
+			Name:     line.params["CN"],
+			PartStat: strings.ToUpper(line.params["PARTSTAT"]),
+		})
+	case "RRULE":
+		e.RRule = line.value
+	case "EXDATE":
+		for _, value := range strings.Split(line.value, ",") {
+			var t time.Time
+			if t, _, err = parseTime(contentLine{params: line.params, value: value}); err != nil {
+				break
+			}
+			e.ExDates = append(e.ExDates, t)
+		}
+	case "RECURRENCE-ID":
+		e.RecurrenceID, _, err = parseTime(line)
+	}
+	return err
+}
+
+// unfold joins continuation lines, which start with a space or tab, onto
+// the line before them.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %v", err)
+	}
+	return lines, nil
+}
+
+func parseContentLine(raw string) (contentLine, bool) {
+	// The value starts at the first colon outside a quoted parameter
+	quoted := false
+	colon := -1
+	for i, r := range raw {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return contentLine{}, false
+	}
+
+	line := contentLine{value: raw[colon+1:], params: make(map[string]string)}
+	parts := splitUnquoted(raw[:colon], ';')
+	line.name = strings.ToUpper(parts[0])
+	for _, param := range parts[1:] {
+		if key, value, ok := strings.Cut(param, "="); ok {
+			line.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+		}
+	}
+	return line, true
+}
+
+func splitUnquoted(s string, sep rune) []string {
+	var (
+		parts  []string
+		start  int
+		quoted bool
+	)
+	for i, r := range s {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == sep && !quoted {
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// parseTime reads a DATE or DATE-TIME value. Times without a zone are in
+// their TZID, or local time when the TZID isn't a known location.
+func parseTime(line contentLine) (time.Time, bool, error) {
+	value := strings.TrimSpace(line.value)
+
+	loc := time.Local
+	if l, ok := location(line.params["TZID"]); ok {
+		loc = l
+	}
+
+	if line.params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, time.Local)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// parseDuration reads an RFC 5545 duration such as "PT1H30M" or "P1D".
+func parseDuration(value string) (time.Duration, error) {
+	s := strings.TrimPrefix(value, "+")
+	sign := time.Duration(1)
+	if strings.HasPrefix(s, "-") {
+		sign = -1
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	s = s[1:]
+
+	var (
+		d      time.Duration
+		number string
+	)
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			number += string(r)
+			continue
+		case r == 'T':
+			continue
+		}
+
+		n, err := strconv.Atoi(number)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		number = ""
+
+		switch r {
+		case 'W':
+			d += time.Duration(n) * 7 * 24 * time.Hour
+		case 'D':
+			d += time.Duration(n) * 24 * time.Hour
+		case 'H':
+			d += time.Duration(n) * time.Hour
+		case 'M':
+			d += time.Duration(n) * time.Minute
+		case 'S':
+			d += time.Duration(n) * time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+	}
+	return sign * d, nil
+}
+
+func mailto(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) > len("mailto:") && strings.EqualFold(value[:len("mailto:")], "mailto:") {
+		value = value[len("mailto:"):]
+	}
+	return strings.ToLower(value)
+}
+
+func unescape(value string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
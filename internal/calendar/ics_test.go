@@ -0,0 +1,79 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:standup",
+		"SUMMARY:Standup\\, daily",
+		"DTSTART;TZID=W. Europe Standard Time:20260113T100000",
+		"DURATION:PT15M",
+		"RRULE:FREQ=DAILY",
+		"ORGANIZER:mailto:Lead@Acme.com",
+		"ATTENDEE;CN=\"Doe, Jane\";PARTSTAT=ACCEPTED:mailto:jane@acme.com",
+		"BEGIN:VALARM",
+		"SUMMARY:Alarm",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"SUMMARY:Broken",
+		"DTSTART:tomorrow",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"SUMMARY:Review with a ",
+		" long title",
+		"DTSTART;TZID=Mars/Olympus_Mons:20260114T090000",
+		"DTEND;TZID=Mars/Olympus_Mons:20260114T100000",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	var warnings []string
+	events, err := Parse(strings.NewReader(ics), func(err error) {
+		warnings = append(warnings, err.Error())
+	})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("events = %d, want 2", len(events))
+	}
+
+	standup := events[0]
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("no time zone database: %v", err)
+	}
+	if want := time.Date(2026, 1, 13, 10, 0, 0, 0, berlin); !standup.Start.Equal(want) {
+		t.Errorf("start = %s, want %s", standup.Start, want)
+	}
+	if got := standup.End.Sub(standup.Start); got != 15*time.Minute {
+		t.Errorf("duration = %s, want 15m", got)
+	}
+	if standup.Summary != "Standup, daily" {
+		t.Errorf("summary = %q", standup.Summary)
+	}
+	if standup.Organizer != "lead@acme.com" {
+		t.Errorf("organizer = %q", standup.Organizer)
+	}
+	if len(standup.Attendees) != 1 || standup.Attendees[0].Name != "Doe, Jane" || standup.Attendees[0].PartStat != "ACCEPTED" {
+		t.Errorf("attendees = %+v", standup.Attendees)
+	}
+
+	if events[1].Summary != "Review with a long title" {
+		t.Errorf("unfolded summary = %q", events[1].Summary)
+	}
+
+	if len(warnings) != 2 ||
+		!strings.Contains(warnings[0], `"Broken"`) ||
+		!strings.Contains(warnings[1], "Mars/Olympus_Mons") {
+		t.Errorf("warnings = %q, want the broken event and the unknown zone", warnings)
+	}
+}
@@ -0,0 +1,327 @@
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxOccurrences bounds how far a rule without COUNT or UNTIL is followed.
+const maxOccurrences = 5000
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// rule is the subset of an RRULE that's expanded: a DAILY, WEEKLY,
+// MONTHLY or YEARLY repeat with INTERVAL, COUNT, UNTIL, BYDAY (with
+// ordinals such as "2TU" or "-1FR" in monthly and yearly rules),
+// BYMONTHDAY, BYMONTH and BYSETPOS.
+type rule struct {
+	freq       string
+	interval   int
+	count      int
+	until      time.Time
+	byDay      []weekdayNum
+	byMonthDay []int
+	byMonth    []time.Month
+	bySetPos   []int
+}
+
+// weekdayNum is a BYDAY entry; n is the ordinal, 0 for every such day.
+type weekdayNum struct {
+	n   int
+	day time.Weekday
+}
+
+func parseRule(value string) (rule, error) {
+	r := rule{interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		key, val, _ := strings.Cut(part, "=")
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.freq = strings.ToUpper(val)
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(val)
+		case "COUNT":
+			r.count, err = strconv.Atoi(val)
+		case "UNTIL":
+			r.until, _, err = parseTime(contentLine{value: val})
+			if err == nil && len(val) == len("20060102") {
+				// A date UNTIL includes the whole day
+				r.until = r.until.AddDate(0, 0, 1)
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(val, ",") {
+				day = strings.ToUpper(strings.TrimSpace(day))
+				name := strings.TrimLeft(day, "+-0123456789")
+				weekday, ok := weekdays[name]
+				if !ok {
+					err = fmt.Errorf("invalid weekday %q", day)
+					break
+				}
+				var n int
+				if ordinal := strings.TrimSuffix(day, name); ordinal != "" {
+					if n, err = strconv.Atoi(ordinal); err != nil {
+						break
+					}
+				}
+				r.byDay = append(r.byDay, weekdayNum{n: n, day: weekday})
+			}
+		case "BYMONTHDAY":
+			r.byMonthDay, err = parseInts(val)
+		case "BYMONTH":
+			var months []int
+			months, err = parseInts(val)
+			for _, month := range months {
+				r.byMonth = append(r.byMonth, time.Month(month))
+			}
+		case "BYSETPOS":
+			r.bySetPos, err = parseInts(val)
+		case "WKST", "":
+		default:
+			// BYHOUR, BYWEEKNO and the like would add occurrences this
+			// doesn't find
+			err = fmt.Errorf("unsupported %s", key)
+		}
+		if err != nil {
+			return rule{}, fmt.Errorf("invalid RRULE %q: %v", value, err)
+		}
+	}
+
+	switch r.freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	default:
+		return rule{}, fmt.Errorf("unsupported RRULE frequency %q", r.freq)
+	}
+	for _, day := range r.byDay {
+		if day.n != 0 && r.freq != "MONTHLY" && r.freq != "YEARLY" {
+			return rule{}, fmt.Errorf("invalid RRULE %q: ordinal weekday in a %s rule", value, strings.ToLower(r.freq))
+		}
+	}
+	if r.freq == "YEARLY" && len(r.byDay) > 0 && len(r.byMonth) == 0 {
+		return rule{}, fmt.Errorf("unsupported RRULE %q: BYDAY in a yearly rule without BYMONTH", value)
+	}
+	if r.interval < 1 {
+		r.interval = 1
+	}
+	return r, nil
+}
+
+func parseInts(value string) ([]int, error) {
+	var ints []int
+	for _, s := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return nil, err
+		}
+		ints = append(ints, n)
+	}
+	return ints, nil
+}
+
+// occurrences returns the start times of e that fall in [from, to),
+// expanding its RRULE and leaving out EXDATEs.
+func (e Event) occurrences(from, to time.Time) ([]time.Time, error) {
+	inRange := func(t time.Time) bool {
+		return !t.Before(from) && t.Before(to)
+	}
+
+	if e.RRule == "" {
+		if inRange(e.Start) {
+			return []time.Time{e.Start}, nil
+		}
+		return nil, nil
+	}
+
+	r, err := parseRule(e.RRule)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := make(map[int64]bool)
+	for _, exdate := range e.ExDates {
+		excluded[exdate.Unix()] = true
+	}
+
+	var (
+		starts []time.Time
+		n      int
+	)
+	emit := func(t time.Time) bool {
+		if t.Before(e.Start) {
+			return true
+		}
+		if !r.until.IsZero() && t.After(r.until) || !t.Before(to) {
+			return false
+		}
+		n++
+		if r.count > 0 && n > r.count || n > maxOccurrences {
+			return false
+		}
+		if inRange(t) && !excluded[t.Unix()] {
+			starts = append(starts, t)
+		}
+		return true
+	}
+
+	for period := 0; period <= maxOccurrences; period++ {
+		for _, t := range r.period(e.Start, period*r.interval) {
+			if !emit(t) {
+				return starts, nil
+			}
+		}
+	}
+	return starts, nil
+}
+
+// period returns the occurrences, in order, of the step'th day, week, month
+// or year after start.
+func (r rule) period(start time.Time, step int) []time.Time {
+	var days []time.Time
+	switch r.freq {
+	case "DAILY":
+		days = []time.Time{start.AddDate(0, 0, step)}
+	case "WEEKLY":
+		if len(r.byDay) == 0 {
+			days = []time.Time{start.AddDate(0, 0, 7*step)}
+			break
+		}
+		// Weeks start on Monday
+		offset := (int(start.Weekday()) + 6) % 7
+		monday := start.AddDate(0, 0, 7*step-offset)
+		for day := 0; day < 7; day++ {
+			days = append(days, monday.AddDate(0, 0, day))
+		}
+	case "MONTHLY":
+		first := time.Date(start.Year(), start.Month()+time.Month(step), 1, 0, 0, 0, 0, start.Location())
+		days = r.monthDays(start, first.Year(), first.Month())
+	case "YEARLY":
+		months := r.byMonth
+		if len(months) == 0 {
+			months = []time.Month{start.Month()}
+		}
+		for _, month := range months {
+			days = append(days, r.monthDays(start, start.Year()+step, month)...)
+		}
+	}
+
+	var times []time.Time
+	for _, t := range days {
+		if r.matches(t) {
+			times = append(times, t)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return r.setPos(times)
+}
+
+// monthDays returns the days of a month a MONTHLY or YEARLY rule picks
+// from, at start's time of day: its BYDAY or BYMONTHDAY days, or start's
+// day of the month.
+func (r rule) monthDays(start time.Time, year int, month time.Month) []time.Time {
+	date := func(day int) time.Time {
+		return time.Date(year, month, day, start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+	}
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+	var days []time.Time
+	switch {
+	case len(r.byDay) > 0:
+		for _, wd := range r.byDay {
+			var matching []int
+			for day := 1; day <= last; day++ {
+				if date(day).Weekday() == wd.day {
+					matching = append(matching, day)
+				}
+			}
+			switch {
+			case wd.n == 0:
+				for _, day := range matching {
+					days = append(days, date(day))
+				}
+			case wd.n > 0 && wd.n <= len(matching):
+				days = append(days, date(matching[wd.n-1]))
+			case wd.n < 0 && -wd.n <= len(matching):
+				days = append(days, date(matching[len(matching)+wd.n]))
+			}
+		}
+	case len(r.byMonthDay) > 0:
+		for _, day := range r.byMonthDay {
+			if day < 0 {
+				day += last + 1
+			}
+			if day >= 1 && day <= last {
+				days = append(days, date(day))
+			}
+		}
+	case start.Day() <= last:
+		// Months without start's day are skipped
+		days = append(days, date(start.Day()))
+	}
+	return days
+}
+
+// matches reports whether t passes the rule's BYDAY, BYMONTHDAY and
+// BYMONTH filters.
+func (r rule) matches(t time.Time) bool {
+	if len(r.byDay) > 0 && !containsWeekday(r.byDay, t.Weekday()) {
+		return false
+	}
+	if len(r.byMonthDay) > 0 {
+		last := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		found := false
+		for _, day := range r.byMonthDay {
+			found = found || day == t.Day() || day < 0 && day+last+1 == t.Day()
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(r.byMonth) > 0 {
+		found := false
+		for _, month := range r.byMonth {
+			found = found || month == t.Month()
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// setPos keeps the BYSETPOS'th of a period's occurrences, counting from
+// the end when negative.
+func (r rule) setPos(times []time.Time) []time.Time {
+	if len(r.bySetPos) == 0 {
+		return times
+	}
+	var kept []time.Time
+	for i, t := range times {
+		for _, pos := range r.bySetPos {
+			if pos == i+1 || pos == i-len(times) {
+				kept = append(kept, t)
+				break
+			}
+		}
+	}
+	return kept
+}
+
+func containsWeekday(days []weekdayNum, day time.Weekday) bool {
+	for _, d := range days {
+		if d.day == day {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOccurrences(t *testing.T) {
+	start := time.Date(2026, 1, 13, 10, 0, 0, 0, time.UTC) // a Tuesday
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		start   time.Time
+		rrule   string
+		exdates []time.Time
+		from    time.Time
+		to      time.Time
+		want    []string // dates of the occurrences
+		wantErr bool
+	}{
+		{
+			name:  "single event",
+			start: start,
+			want:  []string{"2026-01-13"},
+		},
+		{
+			name:  "daily with count",
+			start: start,
+			rrule: "FREQ=DAILY;COUNT=3",
+			want:  []string{"2026-01-13", "2026-01-14", "2026-01-15"},
+		},
+		{
+			name:  "daily on weekdays",
+			start: time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC), // a Friday
+			rrule: "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;COUNT=3",
+			want:  []string{"2026-01-16", "2026-01-19", "2026-01-20"},
+		},
+		{
+			name:    "weekly with exdate",
+			start:   start,
+			rrule:   "FREQ=WEEKLY;UNTIL=20260203",
+			exdates: []time.Time{start.AddDate(0, 0, 7)},
+			want:    []string{"2026-01-13", "2026-01-27", "2026-02-03"},
+		},
+		{
+			name:  "every other week on two days",
+			start: start,
+			rrule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH;WKST=MO;COUNT=4",
+			want:  []string{"2026-01-13", "2026-01-15", "2026-01-27", "2026-01-29"},
+		},
+		{
+			name:  "monthly on the start's day skips short months",
+			start: time.Date(2026, 1, 31, 10, 0, 0, 0, time.UTC),
+			rrule: "FREQ=MONTHLY;COUNT=3",
+			want:  []string{"2026-01-31", "2026-03-31", "2026-05-31"},
+		},
+		{
+			name:  "monthly on the second Tuesday",
+			start: start,
+			rrule: "FREQ=MONTHLY;BYDAY=2TU;COUNT=4",
+			want:  []string{"2026-01-13", "2026-02-10", "2026-03-10", "2026-04-14"},
+		},
+		{
+			name:  "monthly on the last Friday",
+			start: time.Date(2026, 1, 30, 10, 0, 0, 0, time.UTC),
+			rrule: "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3",
+			want:  []string{"2026-01-30", "2026-02-27", "2026-03-27"},
+		},
+		{
+			name:  "monthly on the last weekday",
+			start: time.Date(2026, 1, 30, 10, 0, 0, 0, time.UTC),
+			rrule: "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1;COUNT=3",
+			want:  []string{"2026-01-30", "2026-02-27", "2026-03-31"},
+		},
+		{
+			name:  "monthly by day of the month",
+			start: time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC),
+			rrule: "FREQ=MONTHLY;BYMONTHDAY=1,-1;COUNT=4",
+			want:  []string{"2026-01-01", "2026-01-31", "2026-02-01", "2026-02-28"},
+		},
+		{
+			name:  "yearly on the fourth Thursday of November",
+			start: time.Date(2026, 11, 26, 10, 0, 0, 0, time.UTC),
+			rrule: "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH",
+			from:  from,
+			to:    time.Date(2029, 1, 1, 0, 0, 0, 0, time.UTC),
+			want:  []string{"2026-11-26", "2027-11-25", "2028-11-23"},
+		},
+		{
+			name:  "only the range is returned",
+			start: start,
+			rrule: "FREQ=WEEKLY",
+			from:  time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			to:    time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC),
+			want:  []string{"2026-03-03", "2026-03-10"},
+		},
+		{
+			name:    "unsupported frequency",
+			start:   start,
+			rrule:   "FREQ=HOURLY",
+			wantErr: true,
+		},
+		{
+			name:    "unsupported part",
+			start:   start,
+			rrule:   "FREQ=DAILY;BYHOUR=9,15",
+			wantErr: true,
+		},
+		{
+			name:    "ordinal in a weekly rule",
+			start:   start,
+			rrule:   "FREQ=WEEKLY;BYDAY=1TU",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.from.IsZero() {
+				tt.from, tt.to = from, to
+			}
+			event := Event{Start: tt.start, RRule: tt.rrule, ExDates: tt.exdates}
+
+			starts, err := event.occurrences(tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %t", err, tt.wantErr)
+			}
+
+			var got []string
+			for _, s := range starts {
+				if s.Hour() != tt.start.Hour() {
+					t.Errorf("occurrence %s is not at %s", s, tt.start.Format("15:04"))
+				}
+				got = append(got, s.Format("2006-01-02"))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("occurrences = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("occurrences = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
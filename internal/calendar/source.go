@@ -0,0 +1,258 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/digitaldrywood/timetracker/internal/activity"
+)
+
+// DefaultClient is the project of meetings no rule matches.
+const DefaultClient = "Meetings"
+
+// Rule assigns meetings to a client when an attendee or the organizer has
+// an email in one of Domains (or their subdomains), or the title contains
+// one of Keywords, ignoring case.
+type Rule struct {
+	Client   string
+	Domains  []string
+	Keywords []string
+}
+
+// Options configures a calendar Source.
+type Options struct {
+	// Paths are .ics files or directories searched for them
+	Paths []string
+
+	// Emails are the user's addresses, used to find their reply to an
+	// invitation. Events they declined are skipped.
+	Emails []string
+
+	// Rules are tried in order; the first match picks the client
+	Rules []Rule
+
+	// Warn, if set, is called with the events that are skipped because
+	// they can't be read or expanded, and with unknown time zones.
+	Warn func(error)
+}
+
+// Source reports meetings from exported iCalendar files. All-day,
+// cancelled and declined events are left out.
+type Source struct {
+	opts   Options
+	emails map[string]bool
+}
+
+func NewSource(opts Options) *Source {
+	emails := make(map[string]bool)
+	for _, email := range opts.Emails {
+		emails[strings.ToLower(email)] = true
+	}
+	return &Source{opts: opts, emails: emails}
+}
+
+func (s *Source) Name() string {
+	return "calendar"
+}
+
+func (s *Source) Fetch(ctx context.Context, from, to time.Time) ([]activity.Item, error) {
+	files, err := s.files()
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	for _, file := range files {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		fileEvents, err := s.readFile(file)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, fileEvents...)
+	}
+
+	// Edited occurrences of a recurring event replace the occurrence
+	// they were edited from
+	overridden := make(map[string]bool)
+	for _, event := range events {
+		if !event.RecurrenceID.IsZero() {
+			overridden[occurrenceKey(event.UID, event.RecurrenceID)] = true
+		}
+	}
+
+	var items []activity.Item
+	seen := make(map[string]bool) // the same calendar may be exported twice
+	for _, event := range events {
+		if event.AllDay || event.Status == "CANCELLED" || s.declined(event) {
+			continue
+		}
+
+		starts, err := event.occurrences(from, to)
+		if err != nil {
+			s.warn(fmt.Errorf("skipped event %q: %v", event.Summary, err))
+			continue
+		}
+		for _, start := range starts {
+			key := occurrenceKey(event.UID, start)
+			if event.RRule != "" && overridden[key] || event.UID != "" && seen[key] {
+				continue
+			}
+			seen[key] = true
+			items = append(items, s.item(event, start))
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].Time.Before(items[j].Time) })
+	return items, nil
+}
+
+func (s *Source) item(event Event, start time.Time) activity.Item {
+	var attendees []string
+	for _, attendee := range event.Attendees {
+		if s.emails[attendee.Email] {
+			continue
+		}
+		name := attendee.Name
+		if name == "" {
+			name = attendee.Email
+		}
+		attendees = append(attendees, name)
+	}
+
+	title := event.Summary
+	if title == "" {
+		title = "(no title)"
+	}
+
+	// The client is also the project the meeting is logged against
+	client := s.client(event)
+	project := client
+	if project == "" {
+		project = DefaultClient
+	}
+
+	return activity.Item{
+		Source:     s.Name(),
+		Kind:       activity.KindMeeting,
+		Repository: project,
+		Client:     client,
+		Time:       start,
+		End:        start.Add(event.End.Sub(event.Start)),
+		Title:      title,
+		Body:       strings.Join(attendees, ", "),
+		URL:        event.URL,
+		State:      strings.ToLower(s.partStat(event)),
+	}
+}
+
+// client returns the client of the first rule matching the event, or ""
+// when none does.
+func (s *Source) client(event Event) string {
+	emails := []string{event.Organizer}
+	for _, attendee := range event.Attendees {
+		emails = append(emails, attendee.Email)
+	}
+	title := strings.ToLower(event.Summary)
+
+	for _, rule := range s.opts.Rules {
+		for _, keyword := range rule.Keywords {
+			if keyword != "" && strings.Contains(title, strings.ToLower(keyword)) {
+				return rule.Client
+			}
+		}
+		for _, domain := range rule.Domains {
+			domain = strings.ToLower(strings.TrimPrefix(domain, "@"))
+			for _, email := range emails {
+				_, host, ok := strings.Cut(email, "@")
+				if ok && (host == domain || strings.HasSuffix(host, "."+domain)) {
+					return rule.Client
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// partStat is the user's reply to the event, or "" when they aren't
+// listed as an attendee.
+func (s *Source) partStat(event Event) string {
+	for _, attendee := range event.Attendees {
+		if s.emails[attendee.Email] {
+			return attendee.PartStat
+		}
+	}
+	return ""
+}
+
+func (s *Source) declined(event Event) bool {
+	return s.partStat(event) == "DECLINED"
+}
+
+// files lists the .ics files named by or found under the configured paths.
+func (s *Source) files() ([]string, error) {
+	var files []string
+	for _, path := range s.opts.Paths {
+		if strings.HasPrefix(path, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, path[2:])
+			}
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read calendar %s: %v", path, err)
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.EqualFold(filepath.Ext(file), ".ics") {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to search %s for calendars: %v", path, err)
+		}
+	}
+	return files, nil
+}
+
+func (s *Source) readFile(path string) ([]Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open calendar %s: %v", path, err)
+	}
+	defer f.Close()
+
+	events, err := Parse(f, func(err error) {
+		s.warn(fmt.Errorf("%s: %v", path, err))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse calendar %s: %v", path, err)
+	}
+	return events, nil
+}
+
+func (s *Source) warn(err error) {
+	if s.opts.Warn != nil {
+		s.opts.Warn(err)
+	}
+}
+
+func occurrenceKey(uid string, start time.Time) string {
+	return fmt.Sprintf("%s@%d", uid, start.Unix())
+}
@@ -0,0 +1,81 @@
+package calendar
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSourceFetch(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:kickoff",
+		"SUMMARY:Kickoff",
+		"DTSTART:20260113T100000Z",
+		"DTEND:20260113T110000Z",
+		"ATTENDEE;PARTSTAT=ACCEPTED:mailto:me@example.com",
+		"ATTENDEE:mailto:jane@eng.acme.com",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:planning",
+		"SUMMARY:Globex planning",
+		"DTSTART:20260113T120000Z",
+		"DTEND:20260113T123000Z",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:lunch",
+		"SUMMARY:Lunch",
+		"DTSTART:20260113T130000Z",
+		"DTEND:20260113T140000Z",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:declined",
+		"SUMMARY:Declined",
+		"DTSTART:20260113T150000Z",
+		"DTEND:20260113T160000Z",
+		"ATTENDEE;PARTSTAT=DECLINED:mailto:me@example.com",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "work.ics"), []byte(ics), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	source := NewSource(Options{
+		Paths:  []string{dir},
+		Emails: []string{"Me@example.com"},
+		Rules: []Rule{
+			{Client: "Acme", Domains: []string{"@acme.com"}},
+			{Client: "Globex", Keywords: []string{"globex"}},
+		},
+	})
+	from := time.Date(2026, 1, 13, 0, 0, 0, 0, time.UTC)
+	items, err := source.Fetch(context.Background(), from, from.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+
+	want := []struct{ title, client, project string }{
+		{"Kickoff", "Acme", "Acme"},
+		{"Globex planning", "Globex", "Globex"},
+		{"Lunch", "", DefaultClient},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("items = %d, want %d", len(items), len(want))
+	}
+	for i, w := range want {
+		got := items[i]
+		if got.Title != w.title || got.Client != w.client || got.Repository != w.project {
+			t.Errorf("item %d = %q client %q project %q, want %q client %q project %q",
+				i, got.Title, got.Client, got.Repository, w.title, w.client, w.project)
+		}
+	}
+	if items[0].Body != "jane@eng.acme.com" {
+		t.Errorf("attendees = %q, want the others only", items[0].Body)
+	}
+}
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+
+	"github.com/digitaldrywood/timetracker/internal/activity"
 )
 
 type Config struct {
@@ -11,6 +14,150 @@ type Config struct {
 	TokenPath       string
 	OAuthPort       string
 	OAuthRedirectURL string
+	DataDir         string
+	ConfigPath      string
+	RulesPath       string
+
+	// Settings that don't fit in an environment variable live in a JSON
+	// file at ConfigPath
+	GitHub   GitHubConfig
+	GitLab   []GitLabInstance
+	Identity IdentityConfig
+	Local    LocalSource
+	Daemon   DaemonConfig
+	Calendar CalendarSource
+	Issues   IssuesConfig
+	Learn    LearnConfig
+	Sources  []activity.Spec
+
+	// TaskTypes maps Conventional Commit types to the task names used in
+	// suggestions, on top of the defaults
+	TaskTypes map[string]string
+
+	// DescriptionTemplates are the suggested descriptions by task, on top
+	// of the defaults; "default" covers the rest
+	DescriptionTemplates map[string]string
+}
+
+// fileConfig is the layout of the JSON config file.
+type fileConfig struct {
+	GitHub    GitHubConfig      `json:"github"`
+	GitLab    []GitLabInstance  `json:"gitlab"`
+	Identity  IdentityConfig    `json:"identity"`
+	Local     LocalSource       `json:"local"`
+	Daemon    DaemonConfig      `json:"daemon"`
+	Calendar  CalendarSource    `json:"calendar"`
+	Issues    IssuesConfig      `json:"issues"`
+	Learn     LearnConfig       `json:"learn"`
+	Sources   []activity.Spec   `json:"sources"`
+	TaskTypes map[string]string `json:"task_types"`
+
+	DescriptionTemplates map[string]string `json:"description_templates"`
+}
+
+// IssuesConfig lists the issue tracker key patterns to look for in commit
+// messages, branch names and PR titles, as regular expressions, and the
+// column the keys are written to: "task" (default) or "description".
+type IssuesConfig struct {
+	Patterns []string `json:"patterns"`
+	Column   string   `json:"column"`
+}
+
+// LearnConfig sets where suggestions learn their project, task and hours
+// from: "database" (the default), "sheets" or "off", and how many weeks
+// back to look (12 when zero).
+type LearnConfig struct {
+	Source string `json:"source"`
+	Weeks  int    `json:"weeks"`
+}
+
+// CalendarSource is the settings of a "calendar" activity source: exported
+// .ics files or directories of them, the user's addresses in them
+// (Identity emails when empty) and the rules assigning meetings to clients.
+type CalendarSource struct {
+	Paths   []string         `json:"paths"`
+	Emails  []string         `json:"emails"`
+	Clients []CalendarClient `json:"clients"`
+}
+
+// CalendarClient assigns meetings with attendees from one of Domains, or
+// with one of Keywords in the title, to Client.
+type CalendarClient struct {
+	Client   string   `json:"client"`
+	Domains  []string `json:"domains"`
+	Keywords []string `json:"keywords"`
+}
+
+// DaemonConfig configures `timetracker daemon`, which watches the Local
+// clones for file changes. Durations are Go durations such as "30s".
+type DaemonConfig struct {
+	// Polling scans modification times every PollInterval instead of
+	// using inotify
+	Polling      bool   `json:"polling"`
+	PollInterval string `json:"poll_interval"`
+
+	// Ignore lists file or directory names and globs that aren't activity
+	Ignore []string `json:"ignore"`
+
+	// IdleTimeout is the gap between heartbeats that ends a session
+	IdleTimeout string `json:"idle_timeout"`
+
+	// Retention is how long heartbeats are kept
+	Retention string `json:"retention"`
+}
+
+// HeartbeatSource is the settings of a "heartbeat" activity source.
+type HeartbeatSource struct {
+	IdleTimeout string `json:"idle_timeout"`
+}
+
+// LocalSource is the settings of a "local" or "reflog" activity source:
+// where to look for git clones and how often to look again.
+// RescanInterval is a Go duration such as "12h".
+type LocalSource struct {
+	Roots          []string `json:"roots"`
+	Exclude        []string `json:"exclude"`
+	MaxDepth       int      `json:"max_depth"`
+	RescanInterval string   `json:"rescan_interval"`
+}
+
+// GitLabInstance is gitlab.com or a self-hosted GitLab. The token is read
+// from TokenEnv, then GITLAB_TOKEN.
+type GitLabInstance struct {
+	BaseURL  string `json:"base_url"`
+	TokenEnv string `json:"token_env"`
+}
+
+// IdentityConfig lists the author names and emails your commits may be
+// made under, in addition to your GitHub login. Co-authored-by trailers
+// are matched against the same list.
+type IdentityConfig struct {
+	Names  []string `json:"names"`
+	Emails []string `json:"emails"`
+}
+
+type GitHubConfig struct {
+	// Hosts lists the GitHub instances to collect from. Empty means just
+	// github.com.
+	Hosts []GitHubHost `json:"hosts"`
+
+	// Include and Exclude filter the repositories activity is collected
+	// from. Entries are an org or user ("acme") or a full repository name
+	// ("acme/website"), optionally prefixed with a host
+	// ("github.acme.com/acme"). Exclude wins; an empty Include allows
+	// everything.
+	Include []string `json:"include"`
+	Exclude []string `json:"exclude"`
+}
+
+// GitHubHost is github.com or a GitHub Enterprise Server instance. The
+// token is read from TokenEnv, then GITHUB_TOKEN (github.com) or
+// GH_ENTERPRISE_TOKEN (Enterprise), then `gh auth token --hostname`.
+type GitHubHost struct {
+	Host       string `json:"host"`
+	APIURL     string `json:"api_url"`
+	GraphQLURL string `json:"graphql_url"`
+	TokenEnv   string `json:"token_env"`
 }
 
 func Load() (*Config, error) {
@@ -20,6 +167,9 @@ func Load() (*Config, error) {
 		TokenPath:       os.Getenv("TIMETRACKER_TOKEN_PATH"),
 		OAuthPort:       os.Getenv("TIMETRACKER_OAUTH_PORT"),
 		OAuthRedirectURL: os.Getenv("TIMETRACKER_OAUTH_REDIRECT_URL"),
+		DataDir:         os.Getenv("TIMETRACKER_DATA_DIR"),
+		ConfigPath:      os.Getenv("TIMETRACKER_CONFIG_PATH"),
+		RulesPath:       os.Getenv("TIMETRACKER_RULES_PATH"),
 	}
 
 	// Set defaults if not provided
@@ -35,6 +185,19 @@ func Load() (*Config, error) {
 	if cfg.OAuthRedirectURL == "" {
 		cfg.OAuthRedirectURL = "http://localhost:8080/callback"
 	}
+	if cfg.DataDir == "" {
+		cfg.DataDir = ".local"
+	}
+	if cfg.ConfigPath == "" {
+		cfg.ConfigPath = ".local/config.json"
+	}
+	if cfg.RulesPath == "" {
+		cfg.RulesPath = ".local/rules.json"
+	}
+
+	if err := cfg.loadFile(); err != nil {
+		return nil, err
+	}
 
 	// Validate required fields
 	if cfg.SpreadsheetID == "" {
@@ -42,4 +205,87 @@ func Load() (*Config, error) {
 	}
 
 	return cfg, nil
-}
\ No newline at end of file
+}
+
+// loadFile merges the optional JSON config file into cfg. A missing file is
+// not an error.
+func (cfg *Config) loadFile() error {
+	data, err := os.ReadFile(cfg.ConfigPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %v", cfg.ConfigPath, err)
+	}
+
+	var file fileConfig
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %v", cfg.ConfigPath, err)
+	}
+
+	cfg.GitHub = file.GitHub
+	cfg.GitLab = file.GitLab
+	cfg.Identity = file.Identity
+	cfg.Local = file.Local
+	cfg.Daemon = file.Daemon
+	cfg.Calendar = file.Calendar
+	cfg.Issues = file.Issues
+	cfg.Learn = file.Learn
+	cfg.TaskTypes = file.TaskTypes
+	cfg.DescriptionTemplates = file.DescriptionTemplates
+	cfg.Sources = file.Sources
+
+	return nil
+}
+
+// ActivitySources returns the configured activity sources. Without a
+// "sources" list, every GitHub host and GitLab instance is used, followed by
+// local git clones, their reflogs, the daemon's heartbeats and, when
+// configured, calendar files.
+func (cfg *Config) ActivitySources() ([]activity.Spec, error) {
+	if len(cfg.Sources) > 0 {
+		return cfg.Sources, nil
+	}
+
+	hosts := cfg.GitHub.Hosts
+	if len(hosts) == 0 {
+		hosts = []GitHubHost{{}}
+	}
+
+	var specs []activity.Spec
+	add := func(sourceType string, settings interface{}) error {
+		data, err := json.Marshal(settings)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s source settings: %v", sourceType, err)
+		}
+		specs = append(specs, activity.Spec{Type: sourceType, Settings: data})
+		return nil
+	}
+
+	for _, host := range hosts {
+		if err := add("github", host); err != nil {
+			return nil, err
+		}
+	}
+	for _, instance := range cfg.GitLab {
+		if err := add("gitlab", instance); err != nil {
+			return nil, err
+		}
+	}
+	if err := add("local", cfg.Local); err != nil {
+		return nil, err
+	}
+	if err := add("reflog", cfg.Local); err != nil {
+		return nil, err
+	}
+	if err := add("heartbeat", HeartbeatSource{IdleTimeout: cfg.Daemon.IdleTimeout}); err != nil {
+		return nil, err
+	}
+	if len(cfg.Calendar.Paths) > 0 {
+		if err := add("calendar", cfg.Calendar); err != nil {
+			return nil, err
+		}
+	}
+
+	return specs, nil
+}
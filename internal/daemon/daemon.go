@@ -0,0 +1,291 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/digitaldrywood/timetracker/internal/database"
+	"github.com/digitaldrywood/timetracker/internal/github"
+)
+
+const (
+	DefaultPollInterval = 30 * time.Second
+
+	// heartbeatInterval is the most often one repository is recorded;
+	// editors save far more often than that matters.
+	heartbeatInterval = time.Minute
+
+	// DefaultRetention is how long heartbeats are kept.
+	DefaultRetention = 90 * 24 * time.Hour
+)
+
+// Paths never worth watching, on top of the configured ignore globs
+var defaultIgnore = []string{".git", "node_modules", ".cache"}
+
+// Store records heartbeats. *database.DB implements it.
+type Store interface {
+	AddHeartbeat(heartbeat *database.Heartbeat) error
+	DeleteHeartbeatsBefore(before time.Time) (int64, error)
+}
+
+// Options configures the daemon.
+type Options struct {
+	// Local finds the working trees to watch
+	Local *github.LocalCollector
+	Store Store
+
+	// Ignore lists file or directory names and globs, matched against the
+	// base name and the path relative to the working tree
+	Ignore []string
+
+	// Polling skips inotify and scans modification times every
+	// PollInterval. Platforms without inotify always poll.
+	Polling      bool
+	PollInterval time.Duration
+
+	// Retention is how long heartbeats are kept (DefaultRetention when
+	// zero)
+	Retention time.Duration
+
+	Logf func(format string, args ...interface{})
+}
+
+// watcher reports file changes in the working trees added to it.
+type watcher interface {
+	add(repo github.LocalRepository) error
+	run(ctx context.Context, record func(repo github.LocalRepository, file string, at time.Time)) error
+}
+
+// Run watches the working trees under the configured roots and records a
+// heartbeat whenever files in one of them change, until ctx is done.
+func Run(ctx context.Context, opts Options) error {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = DefaultRetention
+	}
+	if opts.Logf == nil {
+		opts.Logf = func(string, ...interface{}) {}
+	}
+	ignore := append(append([]string{}, defaultIgnore...), opts.Ignore...)
+
+	var w watcher = newPoller(ignore, opts.PollInterval)
+	if !opts.Polling {
+		iw, err := newInotifyWatcher(ignore)
+		if err != nil {
+			opts.Logf("inotify unavailable, polling every %s: %v", opts.PollInterval, err)
+		} else {
+			w = iw
+		}
+	}
+
+	watched := make(map[string]bool)
+	refresh := func() error {
+		repos, err := opts.Local.Repositories(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to discover repositories: %v", err)
+		}
+		for _, repo := range repos {
+			if watched[repo.Path] {
+				continue
+			}
+			if err := w.add(repo); err != nil {
+				opts.Logf("not watching %s: %v", repo.Path, err)
+				continue
+			}
+			watched[repo.Path] = true
+			opts.Logf("watching %s (%s)", repo.Path, repo.Name)
+		}
+
+		if pruned, err := opts.Store.DeleteHeartbeatsBefore(time.Now().Add(-opts.Retention)); err != nil {
+			opts.Logf("failed to prune heartbeats: %v", err)
+		} else if pruned > 0 {
+			opts.Logf("pruned %d heartbeats older than %s", pruned, opts.Retention)
+		}
+		return nil
+	}
+	if err := refresh(); err != nil {
+		return err
+	}
+
+	// The roots are checked for new clones, and old heartbeats pruned, as
+	// often as the local settings rescan them
+	go func() {
+		ticker := time.NewTicker(opts.Local.RescanInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := refresh(); err != nil {
+					opts.Logf("%v", err)
+				}
+			}
+		}
+	}()
+
+	var (
+		mu   sync.Mutex
+		last = make(map[string]time.Time)
+	)
+	record := func(repo github.LocalRepository, file string, at time.Time) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if at.Sub(last[repo.Path]) < heartbeatInterval {
+			return
+		}
+		last[repo.Path] = at
+
+		err := opts.Store.AddHeartbeat(&database.Heartbeat{
+			Repository: repo.Name,
+			Path:       repo.Path,
+			File:       file,
+			At:         at.Truncate(time.Second),
+		})
+		if err != nil {
+			opts.Logf("failed to record heartbeat for %s: %v", repo.Path, err)
+		}
+	}
+
+	err := w.run(ctx, record)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// ignored reports whether rel, a path relative to the working tree, matches
+// any of the patterns by base name or full relative path.
+func ignored(rel string, patterns []string) bool {
+	rel = filepath.ToSlash(rel)
+	name := filepath.Base(rel)
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+		if strings.Contains(pattern, "/") {
+			if ok, _ := filepath.Match(pattern, rel); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// poller finds changes by comparing modification times against the
+// previous scan.
+type poller struct {
+	ignore   []string
+	interval time.Duration
+
+	mu    sync.Mutex
+	repos map[string]github.LocalRepository
+	since map[string]time.Time
+}
+
+func newPoller(ignore []string, interval time.Duration) *poller {
+	return &poller{
+		ignore:   ignore,
+		interval: interval,
+		repos:    make(map[string]github.LocalRepository),
+		since:    make(map[string]time.Time),
+	}
+}
+
+func (p *poller) add(repo github.LocalRepository) error {
+	if _, err := os.Stat(repo.Path); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.repos[repo.Path] = repo
+	p.since[repo.Path] = time.Now()
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *poller) run(ctx context.Context, record func(repo github.LocalRepository, file string, at time.Time)) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		p.mu.Lock()
+		repos := make([]github.LocalRepository, 0, len(p.repos))
+		for _, repo := range p.repos {
+			repos = append(repos, repo)
+		}
+		p.mu.Unlock()
+
+		for _, repo := range repos {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			p.mu.Lock()
+			since := p.since[repo.Path]
+			p.mu.Unlock()
+
+			scanned := time.Now()
+			if file, at, ok := p.newest(repo.Path, since); ok {
+				record(repo, file, at)
+			}
+
+			p.mu.Lock()
+			p.since[repo.Path] = scanned
+			p.mu.Unlock()
+		}
+	}
+}
+
+// newest returns the most recently modified file under root changed after
+// since.
+func (p *poller) newest(root string, since time.Time) (string, time.Time, bool) {
+	var (
+		newestFile string
+		newestAt   time.Time
+	)
+
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == root {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(root, path)
+		if ignored(rel, p.ignore) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if info.ModTime().After(since) && info.ModTime().After(newestAt) {
+			newestFile = rel
+			newestAt = info.ModTime()
+		}
+		return nil
+	})
+
+	return newestFile, newestAt, newestFile != ""
+}
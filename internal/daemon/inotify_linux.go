@@ -0,0 +1,144 @@
+//go:build linux
+
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/digitaldrywood/timetracker/internal/github"
+)
+
+const inotifyMask = syscall.IN_MODIFY | syscall.IN_CREATE | syscall.IN_DELETE |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_CLOSE_WRITE
+
+// inotifyWatcher watches every directory of the working trees, which is
+// cheaper than polling but subject to fs.inotify.max_user_watches.
+type inotifyWatcher struct {
+	fd     int
+	ignore []string
+
+	mu      sync.Mutex
+	watches map[int32]watchedDir
+}
+
+type watchedDir struct {
+	repo github.LocalRepository
+	dir  string
+}
+
+func newInotifyWatcher(ignore []string) (watcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+
+	return &inotifyWatcher{
+		fd:      fd,
+		ignore:  ignore,
+		watches: make(map[int32]watchedDir),
+	}, nil
+}
+
+func (w *inotifyWatcher) add(repo github.LocalRepository) error {
+	return w.addTree(repo, repo.Path)
+}
+
+// addTree watches dir and every directory below it that isn't ignored.
+func (w *inotifyWatcher) addTree(repo github.LocalRepository, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+
+		if path != repo.Path {
+			rel, _ := filepath.Rel(repo.Path, path)
+			if ignored(rel, w.ignore) {
+				return filepath.SkipDir
+			}
+		}
+
+		wd, err := syscall.InotifyAddWatch(w.fd, path, inotifyMask)
+		if err != nil {
+			return fmt.Errorf("failed to watch %s: %v", path, err)
+		}
+
+		w.mu.Lock()
+		w.watches[int32(wd)] = watchedDir{repo: repo, dir: path}
+		w.mu.Unlock()
+		return nil
+	})
+}
+
+func (w *inotifyWatcher) run(ctx context.Context, record func(repo github.LocalRepository, file string, at time.Time)) error {
+	defer syscall.Close(w.fd)
+
+	buf := make([]byte, 64*1024)
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		n, err := syscall.Read(w.fd, buf)
+		if err == syscall.EAGAIN || err == syscall.EINTR {
+			// The descriptor is non-blocking so cancellation is noticed
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(500 * time.Millisecond):
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read inotify events: %v", err)
+		}
+
+		now := time.Now()
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameBytes := buf[offset+syscall.SizeofInotifyEvent : offset+syscall.SizeofInotifyEvent+int(event.Len)]
+			offset += syscall.SizeofInotifyEvent + int(event.Len)
+
+			// The watch is gone once its directory is removed
+			w.mu.Lock()
+			watched, ok := w.watches[event.Wd]
+			if event.Mask&syscall.IN_IGNORED != 0 {
+				delete(w.watches, event.Wd)
+			}
+			w.mu.Unlock()
+
+			name := string(trimNul(nameBytes))
+			if !ok || name == "" {
+				continue
+			}
+
+			path := filepath.Join(watched.dir, name)
+			rel, _ := filepath.Rel(watched.repo.Path, path)
+			if ignored(rel, w.ignore) {
+				continue
+			}
+
+			// New directories need watches of their own
+			if event.Mask&syscall.IN_ISDIR != 0 && event.Mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 {
+				w.addTree(watched.repo, path)
+			}
+
+			record(watched.repo, rel, now)
+		}
+	}
+}
+
+func trimNul(b []byte) []byte {
+	for i, c := range b {
+		if c == 0 {
+			return b[:i]
+		}
+	}
+	return b
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package daemon
+
+import "errors"
+
+func newInotifyWatcher(ignore []string) (watcher, error) {
+	return nil, errors.New("inotify is only supported on Linux")
+}
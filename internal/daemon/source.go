@@ -0,0 +1,83 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/digitaldrywood/timetracker/internal/activity"
+	"github.com/digitaldrywood/timetracker/internal/database"
+)
+
+// DefaultIdleTimeout is how long without file changes ends a session.
+const DefaultIdleTimeout = 15 * time.Minute
+
+// HeartbeatStore reads recorded heartbeats. *database.DB implements it.
+type HeartbeatStore interface {
+	GetHeartbeats(from, to time.Time) ([]database.Heartbeat, error)
+}
+
+// Source reports the daemon's heartbeats as sessions of file activity per
+// repository.
+type Source struct {
+	store       HeartbeatStore
+	idleTimeout time.Duration
+}
+
+func NewSource(store HeartbeatStore, idleTimeout time.Duration) *Source {
+	if idleTimeout <= 0 {
+		idleTimeout = DefaultIdleTimeout
+	}
+	return &Source{store: store, idleTimeout: idleTimeout}
+}
+
+func (s *Source) Name() string {
+	return "heartbeat"
+}
+
+func (s *Source) Fetch(ctx context.Context, from, to time.Time) ([]activity.Item, error) {
+	heartbeats, err := s.store.GetHeartbeats(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get heartbeats: %v", err)
+	}
+
+	var (
+		items []activity.Item
+		files = make(map[int][]string)
+	)
+	open := make(map[string]int) // repository -> index of its latest session
+
+	for _, heartbeat := range heartbeats {
+		i, ok := open[heartbeat.Repository]
+		if !ok || heartbeat.At.Sub(items[i].End) > s.idleTimeout {
+			items = append(items, activity.Item{
+				Source:     s.Name(),
+				Kind:       activity.KindSession,
+				Repository: heartbeat.Repository,
+				Time:       heartbeat.At,
+				URL:        "file://" + heartbeat.Path,
+				Path:       heartbeat.Path,
+				Local:      true,
+			})
+			i = len(items) - 1
+			open[heartbeat.Repository] = i
+		}
+
+		items[i].End = heartbeat.At
+		if heartbeat.File != "" && !slices.Contains(files[i], heartbeat.File) {
+			files[i] = append(files[i], heartbeat.File)
+		}
+	}
+
+	for i := range items {
+		items[i].Title = fmt.Sprintf("Edited %d files", len(files[i]))
+		if len(files[i]) == 1 {
+			items[i].Title = "Edited " + files[i][0]
+		}
+		items[i].Body = strings.Join(files[i], "\n")
+	}
+
+	return items, nil
+}
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// CachedResponse is an API response body stored for reuse across runs.
+type CachedResponse struct {
+	Key       string
+	ETag      string
+	Body      []byte
+	FetchedAt time.Time
+}
+
+// HTTP cache operations
+func (db *DB) GetCachedResponse(key string) (*CachedResponse, error) {
+	var resp CachedResponse
+	var etag sql.NullString
+	err := db.conn.QueryRow(`
+		SELECT key, etag, body, fetched_at
+		FROM http_cache WHERE key = ?
+	`, key).Scan(&resp.Key, &etag, &resp.Body, &resp.FetchedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resp.ETag = etag.String
+	return &resp, nil
+}
+
+func (db *DB) PutCachedResponse(resp *CachedResponse) error {
+	_, err := db.conn.Exec(`
+		INSERT INTO http_cache (key, etag, body, fetched_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET
+			etag = excluded.etag,
+			body = excluded.body,
+			fetched_at = excluded.fetched_at
+	`, resp.Key, resp.ETag, resp.Body, resp.FetchedAt.UTC())
+
+	return err
+}
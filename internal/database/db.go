@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/pressly/goose/v3"
 	_ "modernc.org/sqlite"
@@ -24,12 +25,17 @@ func New(dataDir string) (*DB, error) {
 		return nil, fmt.Errorf("failed to create data directory: %v", err)
 	}
 
+	// The daemon and the CLI write to the same file; wait for each other's
+	// locks instead of failing with SQLITE_BUSY
 	dbPath := filepath.Join(dataDir, "timetracker.db")
-	conn, err := sql.Open("sqlite", dbPath)
+	conn, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %v", err)
 	}
 
+	// SQLite allows a single writer; the GitHub workers share this handle
+	conn.SetMaxOpenConns(1)
+
 	db := &DB{conn: conn}
 	
 	// Run migrations
@@ -43,6 +49,8 @@ func New(dataDir string) (*DB, error) {
 func (db *DB) migrate() error {
 	// Set up goose with embedded migrations
 	goose.SetBaseFS(embedMigrations)
+	// The database is opened on every run; keep migration chatter off stdout
+	goose.SetLogger(goose.NopLogger())
 	
 	if err := goose.SetDialect("sqlite3"); err != nil {
 		return fmt.Errorf("failed to set dialect: %v", err)
@@ -129,9 +137,9 @@ func (db *DB) CreateProject(project *Project) error {
 // Time entry operations
 func (db *DB) CreateTimeEntry(entry *TimeEntry) error {
 	result, err := db.conn.Exec(`
-		INSERT INTO time_entries (project_id, date, hours, description, task_type, billable)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`, entry.ProjectID, entry.Date, entry.Hours, entry.Description, entry.TaskType, entry.Billable)
+		INSERT INTO time_entries (project_id, date, hours, description, task_type, billable, task, repository, activity)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, entry.ProjectID, entry.Date, entry.Hours, entry.Description, entry.TaskType, entry.Billable, entry.Task, entry.Repository, entry.Activity)
 	
 	if err != nil {
 		return err
@@ -146,6 +154,71 @@ func (db *DB) CreateTimeEntry(entry *TimeEntry) error {
 	return nil
 }
 
+// RecordTimeEntry stores an entry logged for a repository, creating the
+// project the first time the repository is seen and, when clientName is
+// given, the client it belongs to.
+func (db *DB) RecordTimeEntry(repoName, clientName string, entry *TimeEntry) error {
+	project, err := db.GetProject(repoName)
+	if err != nil {
+		return fmt.Errorf("failed to get project %s: %v", repoName, err)
+	}
+	if project == nil {
+		project = &Project{RepoName: repoName, Active: true}
+		if clientName != "" {
+			client, err := db.GetClient(clientName)
+			if err != nil {
+				return fmt.Errorf("failed to get client %s: %v", clientName, err)
+			}
+			if client == nil {
+				client = &Client{Name: clientName, Currency: "USD", Active: true}
+				if err := db.CreateClient(client); err != nil {
+					return fmt.Errorf("failed to create client %s: %v", clientName, err)
+				}
+			}
+			project.ClientID = sql.NullInt64{Int64: client.ID, Valid: true}
+		}
+		if err := db.CreateProject(project); err != nil {
+			return fmt.Errorf("failed to create project %s: %v", repoName, err)
+		}
+	}
+
+	entry.ProjectID = project.ID
+	if err := db.CreateTimeEntry(entry); err != nil {
+		return fmt.Errorf("failed to create time entry: %v", err)
+	}
+	return nil
+}
+
+// GetPastEntries returns the time entries dated from from's day up to, but
+// not including, to's day, with the repository their project is named
+// after when the entry didn't record one.
+func (db *DB) GetPastEntries(from, to time.Time) ([]PastEntry, error) {
+	// An expression, so the driver hands the date back as written
+	rows, err := db.conn.Query(`
+		SELECT strftime('%Y-%m-%d', te.date), COALESCE(te.repository, p.repo_name), p.repo_name,
+			COALESCE(te.task, ''), te.hours, te.activity
+		FROM time_entries te
+		JOIN projects p ON p.id = te.project_id
+		WHERE te.date >= ? AND te.date < ?
+		ORDER BY te.date
+	`, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []PastEntry
+	for rows.Next() {
+		var entry PastEntry
+		if err := rows.Scan(&entry.Date, &entry.Repository, &entry.Project, &entry.Task, &entry.Hours, &entry.Activity); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
 // Types
 type Client struct {
 	ID       int64
@@ -174,4 +247,19 @@ type TimeEntry struct {
 	Billable    bool
 	Billed      bool
 	InvoiceID   sql.NullInt64
+
+	// What suggestions learn from; see GetPastEntries
+	Task       sql.NullString
+	Repository sql.NullString
+	Activity   int
+}
+
+// PastEntry is a logged time entry as suggestions learn from it.
+type PastEntry struct {
+	Date       string
+	Repository string
+	Project    string
+	Task       string
+	Hours      float64
+	Activity   int
 }
\ No newline at end of file
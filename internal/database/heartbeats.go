@@ -0,0 +1,70 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Heartbeat records that files in a working tree changed at a point in time.
+type Heartbeat struct {
+	ID         int
+	Repository string
+	Path       string
+	File       string
+	At         time.Time
+}
+
+// Heartbeat operations
+func (db *DB) AddHeartbeat(heartbeat *Heartbeat) error {
+	result, err := db.conn.Exec(`
+		INSERT INTO heartbeats (repository, path, file, at)
+		VALUES (?, ?, ?, ?)
+	`, heartbeat.Repository, heartbeat.Path, heartbeat.File, heartbeat.At.UTC())
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	heartbeat.ID = int(id)
+	return nil
+}
+
+// GetHeartbeats returns the heartbeats in [from, to), oldest first.
+func (db *DB) GetHeartbeats(from, to time.Time) ([]Heartbeat, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, repository, path, file, at
+		FROM heartbeats
+		WHERE at >= ? AND at < ?
+		ORDER BY at
+	`, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var heartbeats []Heartbeat
+	for rows.Next() {
+		var heartbeat Heartbeat
+		var file sql.NullString
+		if err := rows.Scan(&heartbeat.ID, &heartbeat.Repository, &heartbeat.Path, &file, &heartbeat.At); err != nil {
+			return nil, err
+		}
+		heartbeat.File = file.String
+		heartbeats = append(heartbeats, heartbeat)
+	}
+
+	return heartbeats, rows.Err()
+}
+
+// DeleteHeartbeatsBefore removes heartbeats older than before and returns
+// how many there were.
+func (db *DB) DeleteHeartbeatsBefore(before time.Time) (int64, error) {
+	result, err := db.conn.Exec(`DELETE FROM heartbeats WHERE at < ?`, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Local repository discovery cache. GetLocalRepos returns a zero scannedAt
+// when the key has never been scanned.
+func (db *DB) GetLocalRepos(scanKey string) ([]string, time.Time, error) {
+	var scannedAt time.Time
+	err := db.conn.QueryRow(`
+		SELECT scanned_at FROM local_repo_scans WHERE scan_key = ?
+	`, scanKey).Scan(&scannedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, time.Time{}, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	rows, err := db.conn.Query(`
+		SELECT path FROM local_repos WHERE scan_key = ? ORDER BY path
+	`, scanKey)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, time.Time{}, err
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, scannedAt, rows.Err()
+}
+
+// PutLocalRepos replaces the repositories recorded for a scan key.
+func (db *DB) PutLocalRepos(scanKey string, paths []string, scannedAt time.Time) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM local_repos WHERE scan_key = ?`, scanKey); err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO local_repos (scan_key, path) VALUES (?, ?)`, scanKey, path); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO local_repo_scans (scan_key, scanned_at)
+		VALUES (?, ?)
+		ON CONFLICT(scan_key) DO UPDATE SET scanned_at = excluded.scanned_at
+	`, scanKey, scannedAt.UTC()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Cached API responses, revalidated with ETags
+CREATE TABLE IF NOT EXISTS http_cache (
+    key TEXT PRIMARY KEY, -- full request URL
+    etag TEXT,
+    body BLOB NOT NULL,
+    fetched_at DATETIME NOT NULL
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS http_cache;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Git repositories found under a local root, reused until the next rescan
+CREATE TABLE IF NOT EXISTS local_repo_scans (
+    scan_key TEXT PRIMARY KEY, -- root plus the scan settings
+    scanned_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS local_repos (
+    scan_key TEXT NOT NULL,
+    path TEXT NOT NULL,
+    PRIMARY KEY (scan_key, path)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS local_repos;
+DROP TABLE IF EXISTS local_repo_scans;
+-- +goose StatementEnd
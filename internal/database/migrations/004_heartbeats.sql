@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- File activity recorded by the daemon, at most one row per repo a minute
+CREATE TABLE IF NOT EXISTS heartbeats (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    repository TEXT NOT NULL, -- host/owner/repo, as the activity sources name it
+    path TEXT NOT NULL, -- working tree directory
+    file TEXT, -- last file changed, relative to path
+    at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_heartbeats_at ON heartbeats(at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS heartbeats;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- What suggestions learn from: the task as logged, what the suggestion was
+-- built for and how much activity it listed
+ALTER TABLE time_entries ADD COLUMN task TEXT;
+ALTER TABLE time_entries ADD COLUMN repository TEXT; -- host/owner/repo, as the activity sources name it
+ALTER TABLE time_entries ADD COLUMN activity INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_time_entries_date ON time_entries(date);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_time_entries_date;
+ALTER TABLE time_entries DROP COLUMN activity;
+ALTER TABLE time_entries DROP COLUMN repository;
+ALTER TABLE time_entries DROP COLUMN task;
+-- +goose StatementEnd
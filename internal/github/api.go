@@ -0,0 +1,311 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	DefaultHost       = "github.com"
+	DefaultBaseURL    = "https://api.github.com"
+	DefaultGraphQLURL = "https://api.github.com/graphql"
+)
+
+// APIError is returned when the REST or GraphQL endpoint answers with a
+// non-2xx status code.
+type APIError struct {
+	StatusCode int
+	Method     string
+	URL        string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("github: %s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("github: %s %s: %d %s", e.Method, e.URL, e.StatusCode, e.Message)
+}
+
+// RateLimitError is returned when the API still rejects a request for
+// exceeding a rate limit once the retries are used up. Unlike a plain 403 it
+// means the results are incomplete, so it is never skipped over.
+type RateLimitError struct {
+	StatusCode int
+	Method     string
+	URL        string
+	Message    string
+}
+
+func (e *RateLimitError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("github: %s %s: rate limited after %d retries", e.Method, e.URL, maxRetries)
+	}
+	return fmt.Sprintf("github: %s %s: rate limited after %d retries: %s", e.Method, e.URL, maxRetries, e.Message)
+}
+
+// IsRateLimited reports whether err is a *RateLimitError.
+func IsRateLimited(err error) bool {
+	var limitErr *RateLimitError
+	return errors.As(err, &limitErr)
+}
+
+// GraphQLError is returned when a GraphQL request succeeds at the HTTP level
+// but the response carries errors.
+type GraphQLError struct {
+	Errors []GraphQLErrorItem
+}
+
+type GraphQLErrorItem struct {
+	Type    string        `json:"type"`
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path"`
+}
+
+func (e *GraphQLError) Error() string {
+	var messages []string
+	for _, item := range e.Errors {
+		messages = append(messages, item.Message)
+	}
+	return fmt.Sprintf("github: graphql: %s", strings.Join(messages, "; "))
+}
+
+// IsNotFound reports whether err is a 404 from the REST API or a NOT_FOUND
+// GraphQL error.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusNotFound
+	}
+	var gqlErr *GraphQLError
+	if errors.As(err, &gqlErr) {
+		for _, item := range gqlErr.Errors {
+			if item.Type == "NOT_FOUND" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsForbidden reports whether err is a 403 from the API.
+func IsForbidden(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusForbidden
+	}
+	var gqlErr *GraphQLError
+	if errors.As(err, &gqlErr) {
+		for _, item := range gqlErr.Errors {
+			if item.Type == "FORBIDDEN" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolveToken finds a token for host, trying tokenEnv first, then the
+// variables the gh CLI itself honours, then gh's stored login.
+func resolveToken(host, tokenEnv string) (string, error) {
+	keys := []string{"GITHUB_TOKEN", "GH_TOKEN"}
+	if host != DefaultHost {
+		keys = []string{"GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}
+	}
+	if tokenEnv != "" {
+		keys = append([]string{tokenEnv}, keys...)
+	}
+
+	for _, key := range keys {
+		if token := os.Getenv(key); token != "" {
+			return token, nil
+		}
+	}
+
+	// Fall back to the token the gh CLI is already logged in with
+	cmd := exec.Command("gh", "auth", "token", "--hostname", host)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("no %s set and gh auth token for %s failed: %v", keys[0], host, err)
+	}
+
+	token := strings.TrimSpace(string(output))
+	if token == "" {
+		return "", fmt.Errorf("no %s set and gh returned an empty token for %s", keys[0], host)
+	}
+	return token, nil
+}
+
+func (c *Client) newRequest(ctx context.Context, method, rawURL string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+func (c *Client) do(req *http.Request, out interface{}) (*http.Response, error) {
+	resp, data, err := c.send(req)
+	if err != nil {
+		return resp, err
+	}
+
+	if err := checkResponse(req, resp, data); err != nil {
+		return resp, err
+	}
+
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return resp, fmt.Errorf("github: failed to parse response from %s: %v", req.URL, err)
+		}
+	}
+
+	return resp, nil
+}
+
+// checkResponse turns a non-2xx response into an *APIError.
+func checkResponse(req *http.Request, resp *http.Response, data []byte) error {
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Method:     req.Method,
+		URL:        req.URL.String(),
+	}
+	var body struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(data, &body) == nil {
+		apiErr.Message = body.Message
+	}
+	return apiErr
+}
+
+// send performs req, waiting out the primary rate limit beforehand and
+// retrying when the API rejects the call with a primary or secondary limit.
+// A limit that outlasts the retries is a *RateLimitError.
+func (c *Client) send(req *http.Request) (*http.Response, []byte, error) {
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		if delay := c.limiter.delay(); delay > 0 {
+			c.reportProgress(Progress{Stage: "rate limit", Wait: delay})
+			if err := sleep(ctx, delay); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, nil, fmt.Errorf("github: %s %s: %v", req.Method, req.URL, err)
+		}
+
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return resp, nil, fmt.Errorf("github: reading response from %s: %v", req.URL, err)
+		}
+
+		c.limiter.update(resp)
+
+		delay, limited := retryDelay(resp, data, attempt)
+		if !limited {
+			return resp, data, nil
+		}
+		if attempt >= maxRetries {
+			limitErr := &RateLimitError{
+				StatusCode: resp.StatusCode,
+				Method:     req.Method,
+				URL:        req.URL.String(),
+			}
+			if apiErr, ok := checkResponse(req, resp, data).(*APIError); ok {
+				limitErr.Message = apiErr.Message
+			}
+			return resp, data, limitErr
+		}
+
+		c.reportProgress(Progress{Stage: "rate limit", Wait: delay})
+		if err := sleep(ctx, delay); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// get issues a GET against the REST API. path is relative to the base URL.
+func (c *Client) get(ctx context.Context, path string, params url.Values, out interface{}) error {
+	rawURL := c.baseURL + "/" + strings.TrimPrefix(path, "/")
+	if len(params) > 0 {
+		rawURL += "?" + params.Encode()
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.do(req, out)
+	return err
+}
+
+// graphql runs query with variables and decodes the "data" member into out.
+func (c *Client) graphql(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return fmt.Errorf("github: failed to encode graphql request: %v", err)
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, c.graphqlURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Data   json.RawMessage    `json:"data"`
+		Errors []GraphQLErrorItem `json:"errors"`
+	}
+	if _, err := c.do(req, &result); err != nil {
+		return err
+	}
+
+	if len(result.Errors) > 0 {
+		return &GraphQLError{Errors: result.Errors}
+	}
+
+	if out != nil && len(result.Data) > 0 {
+		if err := json.Unmarshal(result.Data, out); err != nil {
+			return fmt.Errorf("github: failed to parse graphql data: %v", err)
+		}
+	}
+
+	return nil
+}
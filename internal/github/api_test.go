@@ -0,0 +1,128 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// newTestClient returns a client for an httptest server running handler.
+// The username is set so New doesn't look it up.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := New(Options{
+		BaseURL:    server.URL,
+		GraphQLURL: server.URL + "/graphql",
+		Token:      "token",
+		Username:   "octocat",
+		HTTPClient: server.Client(),
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return c
+}
+
+func TestSendRetries(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		retryAfter  string
+		message     string
+		failures    int // rejections before the request succeeds
+		wantCalls   int32
+		wantLimited bool
+		wantDenied  bool
+		wantErr     bool
+	}{
+		{name: "success", status: http.StatusOK, wantCalls: 1},
+		{name: "429 then success", status: http.StatusTooManyRequests, retryAfter: "0", failures: 1, wantCalls: 2},
+		{name: "secondary limit then success", status: http.StatusForbidden, retryAfter: "0", message: "You have exceeded a secondary rate limit", failures: 2, wantCalls: 3},
+		{name: "forbidden is not retried", status: http.StatusForbidden, message: "Resource not accessible", failures: 1, wantCalls: 1, wantDenied: true, wantErr: true},
+		{name: "limit outlasts retries", status: http.StatusTooManyRequests, retryAfter: "0", failures: maxRetries + 1, wantCalls: maxRetries + 1, wantLimited: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer token" {
+					t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+				}
+				if int(atomic.AddInt32(&calls, 1)) <= tt.failures {
+					if tt.retryAfter != "" {
+						w.Header().Set("Retry-After", tt.retryAfter)
+					}
+					w.WriteHeader(tt.status)
+					json.NewEncoder(w).Encode(map[string]string{"message": tt.message})
+					return
+				}
+				json.NewEncoder(w).Encode(map[string]string{"login": "octocat"})
+			})
+
+			var user struct {
+				Login string `json:"login"`
+			}
+			err := c.get(context.Background(), "user", nil, &user)
+
+			if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %t", err, tt.wantErr)
+			}
+			if IsRateLimited(err) != tt.wantLimited {
+				t.Errorf("IsRateLimited(%v) = %t, want %t", err, !tt.wantLimited, tt.wantLimited)
+			}
+			if err == nil && user.Login != "octocat" {
+				t.Errorf("login = %q, want octocat", user.Login)
+			}
+			if IsForbidden(err) != tt.wantDenied {
+				t.Errorf("IsForbidden(%v) = %t, want %t", err, !tt.wantDenied, tt.wantDenied)
+			}
+		})
+	}
+}
+
+func TestGraphQL(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request: %v", err)
+			return
+		}
+		if body.Variables["owner"] == "missing" {
+			w.Write([]byte(`{"data":{"repository":null},"errors":[{"type":"NOT_FOUND","message":"Could not resolve to a Repository"}]}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"repository":{"name":"` + body.Variables["name"].(string) + `"}}}`))
+	})
+
+	var out struct {
+		Repository struct {
+			Name string `json:"name"`
+		} `json:"repository"`
+	}
+	err := c.graphql(context.Background(), "query", map[string]interface{}{"owner": "acme", "name": "website"}, &out)
+	if err != nil {
+		t.Fatalf("graphql: %v", err)
+	}
+	if out.Repository.Name != "website" {
+		t.Errorf("name = %q, want website", out.Repository.Name)
+	}
+
+	err = c.graphql(context.Background(), "query", map[string]interface{}{"owner": "missing", "name": "website"}, &out)
+	if !IsNotFound(err) {
+		t.Errorf("IsNotFound(%v) = false", err)
+	}
+}
@@ -0,0 +1,100 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/digitaldrywood/timetracker/internal/database"
+)
+
+// Cache persists REST responses between runs. *database.DB satisfies it.
+type Cache interface {
+	GetCachedResponse(key string) (*database.CachedResponse, error)
+	PutCachedResponse(resp *database.CachedResponse) error
+}
+
+// How long a cached response is trusted without asking GitHub again. Once a
+// TTL expires the request is revalidated with If-None-Match, and a 304 does
+// not count against the rate limit.
+const (
+	noCache          = -1
+	eventsTTL        = 0
+	repoListTTL      = 6 * time.Hour
+	defaultBranchTTL = 7 * 24 * time.Hour
+)
+
+// getCached is get with a response cache in front of it. Without a Cache, or
+// with a noCache TTL, it behaves exactly like get.
+func (c *Client) getCached(ctx context.Context, path string, params url.Values, ttl time.Duration, out interface{}) error {
+	if c.cache == nil || ttl == noCache {
+		return c.get(ctx, path, params, out)
+	}
+
+	rawURL := c.baseURL + "/" + strings.TrimPrefix(path, "/")
+	if len(params) > 0 {
+		rawURL += "?" + params.Encode()
+	}
+	// Responses depend on who is asking, so each login has its own entries
+	key := c.username + "@" + rawURL
+
+	cached, err := c.cache.GetCachedResponse(key)
+	if err != nil {
+		return fmt.Errorf("github: failed to read cache: %v", err)
+	}
+
+	if cached != nil && ttl > 0 && time.Since(cached.FetchedAt) < ttl {
+		return decodeCached(cached, out)
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	if cached != nil && cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	resp, data, err := c.send(req)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		cached.FetchedAt = time.Now()
+		if err := c.cache.PutCachedResponse(cached); err != nil {
+			return fmt.Errorf("github: failed to update cache: %v", err)
+		}
+		return decodeCached(cached, out)
+	}
+
+	if err := checkResponse(req, resp, data); err != nil {
+		return err
+	}
+
+	entry := &database.CachedResponse{
+		Key:       key,
+		ETag:      resp.Header.Get("ETag"),
+		Body:      data,
+		FetchedAt: time.Now(),
+	}
+	if err := c.cache.PutCachedResponse(entry); err != nil {
+		return fmt.Errorf("github: failed to write cache: %v", err)
+	}
+
+	return decodeCached(entry, out)
+}
+
+func decodeCached(entry *database.CachedResponse, out interface{}) error {
+	if out == nil || len(entry.Body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(entry.Body, out); err != nil {
+		return fmt.Errorf("github: failed to parse cached response for %s: %v", entry.Key, err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/digitaldrywood/timetracker/internal/database"
+)
+
+// memoryCache is a Cache kept in memory.
+type memoryCache struct {
+	mu        sync.Mutex
+	responses map[string]database.CachedResponse
+}
+
+func (m *memoryCache) GetCachedResponse(key string) (*database.CachedResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	resp, ok := m.responses[key]
+	if !ok {
+		return nil, nil
+	}
+	return &resp, nil
+}
+
+func (m *memoryCache) PutCachedResponse(resp *database.CachedResponse) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responses[resp.Key] = *resp
+	return nil
+}
+
+func TestGetCached(t *testing.T) {
+	var calls, revalidated int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&revalidated, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte(`{"default_branch":"main"}`))
+	})
+	cache := &memoryCache{responses: make(map[string]database.CachedResponse)}
+	c.cache = cache
+
+	get := func(ttl time.Duration) string {
+		t.Helper()
+		var repo struct {
+			DefaultBranch string `json:"default_branch"`
+		}
+		if err := c.getCached(context.Background(), "repos/acme/website", nil, ttl, &repo); err != nil {
+			t.Fatalf("getCached: %v", err)
+		}
+		return repo.DefaultBranch
+	}
+
+	// The first request fills the cache
+	if branch := get(time.Hour); branch != "main" {
+		t.Errorf("branch = %q, want main", branch)
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("calls = %d, want 1", atomic.LoadInt32(&calls))
+	}
+
+	// Within the TTL the cache answers
+	if branch := get(time.Hour); branch != "main" {
+		t.Errorf("cached branch = %q, want main", branch)
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("calls within TTL = %d, want 1", atomic.LoadInt32(&calls))
+	}
+
+	// Past it the ETag is revalidated and the 304 served from the cache
+	if branch := get(eventsTTL); branch != "main" {
+		t.Errorf("revalidated branch = %q, want main", branch)
+	}
+	if atomic.LoadInt32(&calls) != 2 || atomic.LoadInt32(&revalidated) != 1 {
+		t.Errorf("calls = %d, revalidated = %d, want 2 and 1", atomic.LoadInt32(&calls), atomic.LoadInt32(&revalidated))
+	}
+
+	// Without caching the server is always asked, without an ETag
+	get(noCache)
+	if atomic.LoadInt32(&calls) != 3 || atomic.LoadInt32(&revalidated) != 1 {
+		t.Errorf("uncached calls = %d, revalidated = %d, want 3 and 1", atomic.LoadInt32(&calls), atomic.LoadInt32(&revalidated))
+	}
+}
+
+func TestGetCachedPerLogin(t *testing.T) {
+	var calls int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(`{"private":true}`))
+	})
+	c.cache = &memoryCache{responses: make(map[string]database.CachedResponse)}
+
+	for _, login := range []string{"octocat", "hubot", "octocat"} {
+		c.username = login
+		if err := c.getCached(context.Background(), "user/repos", nil, time.Hour, nil); err != nil {
+			t.Fatalf("getCached as %s: %v", login, err)
+		}
+	}
+	// octocat's response is never served to hubot
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("calls = %d, want 2", got)
+	}
+}
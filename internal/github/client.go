@@ -1,23 +1,91 @@
 package github
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"os/exec"
+	"net/http"
+	"net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
 type Client struct {
-	username string
+	host        string
+	username    string
+	token       string
+	baseURL     string
+	graphqlURL  string
+	httpClient  *http.Client
+	concurrency int
+	limiter     *rateLimiter
+	cache       Cache
+	include     []string
+	exclude     []string
+	identity    Identity
+	progress    func(Progress)
+	progressMu  sync.Mutex
+	warn        func(error)
+
+	discoveryMu sync.Mutex
+	discovered  map[string][]string
+}
+
+// Options configures a Client. Zero values fall back to github.com and the
+// token from the environment or the gh CLI.
+type Options struct {
+	// Host is the GitHub hostname, e.g. "github.com" or a GitHub
+	// Enterprise Server such as "github.acme.com". The API URLs are derived
+	// from it unless BaseURL and GraphQLURL are set.
+	Host       string
+	BaseURL    string
+	GraphQLURL string
+	Token      string
+	TokenEnv   string
+	Username   string
+	HTTPClient *http.Client
+
+	// Concurrency bounds how many repositories are fetched at once.
+	Concurrency int
+
+	// Include and Exclude filter discovered repositories. Entries are an
+	// owner ("acme") or a full name ("acme/website").
+	Include []string
+	Exclude []string
+
+	// Identity lists extra author names and emails that count as the user
+	// when matching commits. The GitHub login is always included.
+	Identity Identity
+
+	// Cache, if set, stores REST responses across runs and revalidates
+	// them with ETags.
+	Cache Cache
+
+	// Progress, if set, is called as repositories finish and while waiting
+	// on rate limits. Calls are serialised.
+	Progress func(Progress)
+
+	// Warn, if set, is called with the repositories that failed and were
+	// left out. Without it their errors are returned, with the results of
+	// the others.
+	Warn func(error)
 }
 
 type Commit struct {
-	SHA        string
-	Message    string
-	URL        string
-	Repository string
-	AuthorDate time.Time
+	SHA         string
+	Message     string
+	URL         string
+	Repository  string
+	AuthorDate  time.Time
+	Branch      string
+	PullRequest int
+	Unpushed    bool   // local commit not on any remote-tracking branch
+	Path        string // working tree of the local clone it was found in
+
+	// Diff stats; FilesChanged is 0 where the provider doesn't report it
+	Additions    int
+	Deletions    int
+	FilesChanged int
 }
 
 type PullRequest struct {
@@ -31,275 +99,253 @@ type PullRequest struct {
 }
 
 func NewClient() (*Client, error) {
-	username, err := getCurrentUser()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get current user: %v", err)
-	}
-
-	return &Client{username: username}, nil
+	return New(Options{})
 }
 
-func getCurrentUser() (string, error) {
-	cmd := exec.Command("gh", "api", "user", "--jq", ".login")
-	output, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("failed to get current user from gh: %v", err)
+func New(opts Options) (*Client, error) {
+	c := &Client{
+		host:        opts.Host,
+		username:    opts.Username,
+		token:       opts.Token,
+		baseURL:     strings.TrimSuffix(opts.BaseURL, "/"),
+		graphqlURL:  opts.GraphQLURL,
+		httpClient:  opts.HTTPClient,
+		concurrency: opts.Concurrency,
+		limiter:     &rateLimiter{},
+		cache:       opts.Cache,
+		include:     opts.Include,
+		exclude:     opts.Exclude,
+		identity:    opts.Identity,
+		discovered:  make(map[string][]string),
+		progress:    opts.Progress,
+		warn:        opts.Warn,
 	}
 
-	return strings.TrimSpace(string(output)), nil
-}
-
-func (c *Client) GetTodayCommits() ([]Commit, error) {
-	today := time.Now().Format("2006-01-02")
-	return c.GetCommitsSince(today)
-}
-
-func (c *Client) GetCommitsSince(since string) ([]Commit, error) {
-	repos, err := c.getRecentRepositories()
-	if err != nil {
-		return nil, err
+	if c.host == "" {
+		c.host = DefaultHost
 	}
-
-	var allCommits []Commit
-
-	for _, repo := range repos {
-		commits, err := c.getRepositoryCommits(repo, since)
-		if err != nil {
-			continue
+	if c.baseURL == "" {
+		c.baseURL = DefaultBaseURL
+		if c.host != DefaultHost {
+			// GitHub Enterprise Server serves the API under the host itself
+			c.baseURL = "https://" + c.host + "/api/v3"
 		}
-		allCommits = append(allCommits, commits...)
 	}
-
-	return allCommits, nil
-}
-
-func (c *Client) getRecentRepositories() ([]string, error) {
-	// Use GitHub events API to find ALL repos the user has been active in
-	cmd := exec.Command("gh", "api", fmt.Sprintf("users/%s/events/public", c.username), "--paginate", "--jq", ".[].repo.name")
-	output, err := cmd.Output()
-	if err != nil {
-		// Fallback to owned repos if events fail
-		return c.getOwnedRepositories()
+	if c.graphqlURL == "" {
+		switch {
+		case c.baseURL == DefaultBaseURL:
+			c.graphqlURL = DefaultGraphQLURL
+		case strings.HasSuffix(c.baseURL, "/api/v3"):
+			c.graphqlURL = strings.TrimSuffix(c.baseURL, "/v3") + "/graphql"
+		default:
+			c.graphqlURL = c.baseURL + "/graphql"
+		}
+	}
+	if c.httpClient == nil {
+		c.httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	if c.concurrency <= 0 {
+		c.concurrency = DefaultConcurrency
 	}
 
-	// Parse unique repo names from events
-	repoMap := make(map[string]bool)
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		repo := strings.TrimSpace(line)
-		if repo != "" {
-			repoMap[repo] = true
+	if c.token == "" {
+		token, err := resolveToken(c.host, opts.TokenEnv)
+		if err != nil {
+			return nil, err
 		}
+		c.token = token
 	}
-	
-	// Also get owned repos to ensure we don't miss any
-	ownedRepos, err := c.getOwnedRepositories()
-	if err == nil {
-		for _, repo := range ownedRepos {
-			repoMap[repo] = true
+
+	if c.username == "" {
+		username, err := c.getCurrentUser(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current user on %s: %v", c.host, err)
 		}
+		c.username = username
 	}
-	
-	// Convert map to slice
-	var repoNames []string
-	for repo := range repoMap {
-		repoNames = append(repoNames, repo)
+
+	if !containsFold(c.identity.Logins, c.username) {
+		c.identity.Logins = append(c.identity.Logins, c.username)
 	}
 
-	return repoNames, nil
+	return c, nil
 }
 
-func (c *Client) getOwnedRepositories() ([]string, error) {
-	cmd := exec.Command("gh", "repo", "list", "--limit", "100", "--json", "nameWithOwner")
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("failed to list repositories: %v", err)
-	}
+// Host returns the GitHub hostname this client talks to.
+func (c *Client) Host() string {
+	return c.host
+}
 
-	var repos []struct {
-		NameWithOwner string `json:"nameWithOwner"`
-	}
+// Username returns the login of the authenticated user.
+func (c *Client) Username() string {
+	return c.username
+}
 
-	if err := json.Unmarshal(output, &repos); err != nil {
-		return nil, fmt.Errorf("failed to parse repository list: %v", err)
-	}
+// repoID qualifies an owner/name pair with the host, which is how
+// repositories are identified outside this package.
+func (c *Client) repoID(fullName string) string {
+	return c.host + "/" + fullName
+}
 
-	var repoNames []string
-	for _, r := range repos {
-		repoNames = append(repoNames, r.NameWithOwner)
+func (c *Client) getCurrentUser(ctx context.Context) (string, error) {
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := c.get(ctx, "user", nil, &user); err != nil {
+		return "", err
 	}
 
-	return repoNames, nil
+	return user.Login, nil
 }
 
-func (c *Client) getRepositoryCommits(repo string, since string) ([]Commit, error) {
-	// Try to get commits using gh repo view to find default branch
-	// Then use git log style command which is more reliable
-	cmd := exec.Command("gh", "repo", "view", repo, "--json", "defaultBranchRef")
-	branchOutput, err := cmd.Output()
-	if err != nil {
-		return nil, nil
-	}
-	
-	var branchInfo struct {
-		DefaultBranchRef struct {
-			Name string `json:"name"`
-		} `json:"defaultBranchRef"`
-	}
-	
-	if err := json.Unmarshal(branchOutput, &branchInfo); err != nil {
-		return nil, nil
-	}
-	
-	branch := branchInfo.DefaultBranchRef.Name
-	if branch == "" {
-		branch = "main" // fallback
-	}
-	
-	// Use gh api to get commits with the correct branch
-	query := fmt.Sprintf(`
-		query {
-			repository(owner: "%s", name: "%s") {
-				ref(qualifiedName: "refs/heads/%s") {
-					target {
-						... on Commit {
-							history(first: 100, since: "%sT00:00:00Z") {
-								edges {
-									node {
-										oid
-										message
-										url
-										authoredDate
-										author {
-											user {
-												login
-											}
-										}
-									}
-								}
-							}
-						}
-					}
-				}
-			}
-		}
-	`, strings.Split(repo, "/")[0], strings.Split(repo, "/")[1], branch, since)
+// today returns the start of the current local day and of the next one.
+func today() (time.Time, time.Time) {
+	return DayRange(time.Now())
+}
 
-	cmd = exec.Command("gh", "api", "graphql", "-f", fmt.Sprintf("query=%s", query))
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, nil
-	}
+// DayRange returns the start of date's local day and of the next one, the
+// range the Get* methods take to report a single day.
+func DayRange(date time.Time) (time.Time, time.Time) {
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	return start, start.AddDate(0, 0, 1)
+}
 
-	var result struct {
-		Data struct {
-			Repository struct {
-				Ref struct {
-					Target struct {
-						History struct {
-							Edges []struct {
-								Node struct {
-									OID          string    `json:"oid"`
-									Message      string    `json:"message"`
-									URL          string    `json:"url"`
-									AuthoredDate time.Time `json:"authoredDate"`
-									Author       struct {
-										User struct {
-											Login string `json:"login"`
-										} `json:"user"`
-									} `json:"author"`
-								} `json:"node"`
-							} `json:"edges"`
-						} `json:"history"`
-					} `json:"target"`
-				} `json:"ref"`
-			} `json:"repository"`
-		} `json:"data"`
-	}
+func (c *Client) GetTodayCommits(ctx context.Context) ([]Commit, error) {
+	from, to := today()
+	return c.GetCommits(ctx, from, to)
+}
 
-	if err := json.Unmarshal(output, &result); err != nil {
-		return nil, nil
+// GetCommits returns the user's commits authored between from and to
+// across every repository they were active in.
+func (c *Client) GetCommits(ctx context.Context, from, to time.Time) ([]Commit, error) {
+	repos, err := c.getRecentRepositories(ctx, from)
+	if err != nil {
+		return nil, err
 	}
 
-	var commits []Commit
-	for _, edge := range result.Data.Repository.Ref.Target.History.Edges {
-		// Filter by author to only include current user's commits
-		if edge.Node.Author.User.Login == c.username {
-			commits = append(commits, Commit{
-				SHA:        edge.Node.OID,
-				Message:    edge.Node.Message,
-				URL:        edge.Node.URL,
-				Repository: repo,
-				AuthorDate: edge.Node.AuthoredDate,
-			})
+	var (
+		mu         sync.Mutex
+		allCommits []Commit
+	)
+
+	err = c.forEachRepo(ctx, "commits", repos, func(ctx context.Context, repo string) error {
+		commits, err := c.getRepositoryCommits(ctx, repo, from, to)
+		if err != nil {
+			// Repos we can no longer see are not worth failing the run for
+			if IsNotFound(err) || IsForbidden(err) {
+				return nil
+			}
+			if IsRateLimited(err) {
+				return err
+			}
+			return fmt.Errorf("failed to get commits for %s: %v", repo, err)
 		}
-	}
 
-	return commits, nil
+		mu.Lock()
+		allCommits = append(allCommits, commits...)
+		mu.Unlock()
+		return nil
+	})
+	return allCommits, err
+}
+
+func (c *Client) GetTodayPullRequests(ctx context.Context) ([]PullRequest, error) {
+	from, to := today()
+	return c.GetPullRequests(ctx, from, to)
 }
 
-func (c *Client) GetRecentPullRequests(days int) ([]PullRequest, error) {
+// GetPullRequests returns the user's pull requests created or updated
+// between from and to.
+func (c *Client) GetPullRequests(ctx context.Context, from, to time.Time) ([]PullRequest, error) {
 	// Get recent repos that might have PRs
-	repos, err := c.getRecentRepositories()
+	repos, err := c.getRecentRepositories(ctx, from)
 	if err != nil {
 		return nil, err
 	}
-	
-	var allPRs []PullRequest
-	cutoffDate := time.Now().AddDate(0, 0, -days)
-	
+
+	var (
+		mu     sync.Mutex
+		allPRs []PullRequest
+	)
+
 	// Check each repo for recent PRs
-	for _, repo := range repos {
-		cmd := exec.Command("gh", "pr", "list",
-			"--repo", repo,
-			"--author", c.username,
-			"--state", "all",
-			"--json", "number,title,url,state,createdAt,updatedAt",
-			"--limit", "20")
-		
-		output, err := cmd.CombinedOutput()
+	err = c.forEachRepo(ctx, "pull requests", repos, func(ctx context.Context, repo string) error {
+		prs, err := c.getRepositoryPullRequests(ctx, repo, from, to)
 		if err != nil {
-			// Skip repos with errors (might not have PR access)
-			continue
-		}
-		
-		// Handle empty results
-		if len(output) == 0 || string(output) == "[]\n" || string(output) == "[]" {
-			continue
+			// Skip repos we might not have PR access to
+			if IsNotFound(err) || IsForbidden(err) {
+				return nil
+			}
+			if IsRateLimited(err) {
+				return err
+			}
+			return fmt.Errorf("failed to get pull requests for %s: %v", repo, err)
 		}
-		
-		var prs []struct {
-			Number    int       `json:"number"`
-			Title     string    `json:"title"`
-			URL       string    `json:"url"`
-			State     string    `json:"state"`
-			CreatedAt time.Time `json:"createdAt"`
-			UpdatedAt time.Time `json:"updatedAt"`
+
+		mu.Lock()
+		allPRs = append(allPRs, prs...)
+		mu.Unlock()
+		return nil
+	})
+	return allPRs, err
+}
+
+// getRepositoryPullRequests pages through a repository's pull requests,
+// most recently updated first, until they fall before from.
+func (c *Client) getRepositoryPullRequests(ctx context.Context, repo string, from, to time.Time) ([]PullRequest, error) {
+	type pullRequest struct {
+		Number    int       `json:"number"`
+		Title     string    `json:"title"`
+		HTMLURL   string    `json:"html_url"`
+		State     string    `json:"state"`
+		MergedAt  *string   `json:"merged_at"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+		User      struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	}
+
+	params := url.Values{
+		"state":     {"all"},
+		"sort":      {"updated"},
+		"direction": {"desc"},
+	}
+
+	inRange := func(t time.Time) bool {
+		return !t.Before(from) && t.Before(to)
+	}
+
+	var results []PullRequest
+	err := getAllPages(ctx, c, fmt.Sprintf("repos/%s/pulls", repo), params, noCache, func(pr pullRequest) bool {
+		if pr.UpdatedAt.Before(from) {
+			return false
 		}
-		
-		if err := json.Unmarshal(output, &prs); err != nil {
-			continue
+		if pr.User.Login != c.username {
+			return true
 		}
-		
+
 		// Filter by date and add to results
-		for _, pr := range prs {
-			if pr.CreatedAt.After(cutoffDate) || pr.UpdatedAt.After(cutoffDate) {
-				allPRs = append(allPRs, PullRequest{
-					Number:     pr.Number,
-					Title:      pr.Title,
-					URL:        pr.URL,
-					Repository: repo,
-					State:      pr.State,
-					CreatedAt:  pr.CreatedAt,
-					UpdatedAt:  pr.UpdatedAt,
-				})
+		if inRange(pr.CreatedAt) || inRange(pr.UpdatedAt) {
+			state := strings.ToUpper(pr.State)
+			if pr.MergedAt != nil {
+				state = "MERGED"
 			}
+			results = append(results, PullRequest{
+				Number:     pr.Number,
+				Title:      pr.Title,
+				URL:        pr.HTMLURL,
+				Repository: c.repoID(repo),
+				State:      state,
+				CreatedAt:  pr.CreatedAt,
+				UpdatedAt:  pr.UpdatedAt,
+			})
 		}
+		return true
+	})
+	if err != nil {
+		return nil, err
 	}
-	
-	return allPRs, nil
-}
 
-func (c *Client) GetTodayPullRequests() ([]PullRequest, error) {
-	return c.GetRecentPullRequests(1)
+	return results, nil
 }
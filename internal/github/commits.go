@@ -0,0 +1,416 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// commitDateSlack is how far before the window commits are asked for.
+// git and the API filter on the committer date, which clock skew can put
+// before the author date; rebases, amends and cherry-picks move it later, so
+// there's no upper bound. The author date decides what's kept.
+const commitDateSlack = 24 * time.Hour
+
+// branchCommitsQuery walks every branch, most recently committed first,
+// with the first page of each branch's history since the window opened.
+const branchCommitsQuery = `
+query($owner: String!, $name: String!, $since: GitTimestamp!, $cursor: String) {
+	repository(owner: $owner, name: $name) {
+		refs(refPrefix: "refs/heads/", first: 25, after: $cursor, orderBy: {field: TAG_COMMIT_DATE, direction: DESC}) {
+			pageInfo {
+				hasNextPage
+				endCursor
+			}
+			nodes {
+				name
+				target {
+					... on Commit {
+						committedDate
+						history(first: 100, since: $since) {
+							pageInfo {
+								hasNextPage
+								endCursor
+							}
+							nodes {
+								...commitFields
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}
+` + commitFieldsFragment
+
+// refHistoryQuery pages through the rest of one branch's history since the
+// window opened.
+const refHistoryQuery = `
+query($owner: String!, $name: String!, $ref: String!, $since: GitTimestamp!, $cursor: String) {
+	repository(owner: $owner, name: $name) {
+		ref(qualifiedName: $ref) {
+			target {
+				... on Commit {
+					history(first: 100, since: $since, after: $cursor) {
+						pageInfo {
+							hasNextPage
+							endCursor
+						}
+						nodes {
+							...commitFields
+						}
+					}
+				}
+			}
+		}
+	}
+}
+` + commitFieldsFragment
+
+// pullRequestCommitsQuery finds the user's pull requests through search,
+// which can scope them by author and date. PR commit lists catch work
+// whose branch was deleted after a squash merge.
+const pullRequestCommitsQuery = `
+query($search: String!, $cursor: String) {
+	search(query: $search, type: ISSUE, first: 25, after: $cursor) {
+		pageInfo {
+			hasNextPage
+			endCursor
+		}
+		nodes {
+			... on PullRequest {
+				number
+				headRefName
+				commits(last: 100) {
+					nodes {
+						commit {
+							...commitFields
+						}
+					}
+				}
+			}
+		}
+	}
+}
+` + commitFieldsFragment
+
+const commitFieldsFragment = `
+fragment commitFields on Commit {
+	oid
+	message
+	url
+	authoredDate
+	additions
+	deletions
+	changedFilesIfAvailable
+	author {
+		name
+		email
+		user {
+			login
+		}
+	}
+	associatedPullRequests(first: 1) {
+		nodes {
+			number
+		}
+	}
+}`
+
+type commitHistory struct {
+	PageInfo struct {
+		HasNextPage bool   `json:"hasNextPage"`
+		EndCursor   string `json:"endCursor"`
+	} `json:"pageInfo"`
+	Nodes []graphCommit `json:"nodes"`
+}
+
+type graphCommit struct {
+	OID          string    `json:"oid"`
+	Message      string    `json:"message"`
+	URL          string    `json:"url"`
+	AuthoredDate time.Time `json:"authoredDate"`
+	Additions    int       `json:"additions"`
+	Deletions    int       `json:"deletions"`
+	ChangedFiles *int      `json:"changedFilesIfAvailable"`
+	Author       struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+		User  *struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"author"`
+	AssociatedPullRequests struct {
+		Nodes []struct {
+			Number int `json:"number"`
+		} `json:"nodes"`
+	} `json:"associatedPullRequests"`
+}
+
+// getRepositoryCommits collects the user's commits authored between from
+// and to from every branch and from their pull requests, one record per
+// SHA.
+func (c *Client) getRepositoryCommits(ctx context.Context, repo string, from, to time.Time) ([]Commit, error) {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid repository name %q", repo)
+	}
+
+	defaultBranch, err := c.getDefaultBranch(ctx, owner, name)
+	if err != nil {
+		return nil, err
+	}
+
+	collector := newCommitCollector(c, c.repoID(repo))
+	collector.defaultBranch = defaultBranch
+
+	since := from.Add(-commitDateSlack)
+	var cursor interface{}
+	for page := 0; page < maxPages; page++ {
+		var result struct {
+			Repository *struct {
+				Refs struct {
+					PageInfo struct {
+						HasNextPage bool   `json:"hasNextPage"`
+						EndCursor   string `json:"endCursor"`
+					} `json:"pageInfo"`
+					Nodes []struct {
+						Name   string `json:"name"`
+						Target struct {
+							CommittedDate time.Time     `json:"committedDate"`
+							History       commitHistory `json:"history"`
+						} `json:"target"`
+					} `json:"nodes"`
+				} `json:"refs"`
+			} `json:"repository"`
+		}
+
+		variables := map[string]interface{}{
+			"owner":  owner,
+			"name":   name,
+			"since":  since.UTC().Format(time.RFC3339),
+			"cursor": cursor,
+		}
+		if err := c.graphql(ctx, branchCommitsQuery, variables, &result); err != nil {
+			return nil, err
+		}
+		if result.Repository == nil {
+			return nil, nil
+		}
+
+		refs := result.Repository.Refs
+		stale := false
+		for _, ref := range refs.Nodes {
+			// Branches come newest first; once a tip predates the window
+			// nothing after it can contain new commits
+			if ref.Target.CommittedDate.Before(since) {
+				stale = true
+				break
+			}
+			history := ref.Target.History
+			for {
+				for _, node := range history.Nodes {
+					if between(node.AuthoredDate, from, to) {
+						collector.add(node, ref.Name, 0)
+					}
+				}
+				if !history.PageInfo.HasNextPage {
+					break
+				}
+				next, err := c.getRefHistory(ctx, owner, name, ref.Name, since, history.PageInfo.EndCursor)
+				if err != nil {
+					return nil, err
+				}
+				history = next
+			}
+		}
+
+		if stale || !refs.PageInfo.HasNextPage {
+			break
+		}
+		cursor = refs.PageInfo.EndCursor
+	}
+
+	if err := c.addPullRequestCommits(ctx, collector, repo, from, to); err != nil {
+		return nil, err
+	}
+
+	return collector.commits(), nil
+}
+
+// getRefHistory fetches the page of a branch's history since since after
+// cursor.
+func (c *Client) getRefHistory(ctx context.Context, owner, name, ref string, since time.Time, cursor string) (commitHistory, error) {
+	var result struct {
+		Repository *struct {
+			Ref *struct {
+				Target struct {
+					History commitHistory `json:"history"`
+				} `json:"target"`
+			} `json:"ref"`
+		} `json:"repository"`
+	}
+
+	variables := map[string]interface{}{
+		"owner":  owner,
+		"name":   name,
+		"ref":    "refs/heads/" + ref,
+		"since":  since.UTC().Format(time.RFC3339),
+		"cursor": cursor,
+	}
+	if err := c.graphql(ctx, refHistoryQuery, variables, &result); err != nil {
+		return commitHistory{}, err
+	}
+
+	// A branch deleted between pages has nothing more to give
+	if result.Repository == nil || result.Repository.Ref == nil {
+		return commitHistory{}, nil
+	}
+	return result.Repository.Ref.Target.History, nil
+}
+
+// addPullRequestCommits adds the commits authored between from and to on
+// the user's pull requests updated since from. Commits often predate the
+// PR, so its creation date can't narrow the search.
+func (c *Client) addPullRequestCommits(ctx context.Context, collector *commitCollector, repo string, from, to time.Time) error {
+	search := fmt.Sprintf("repo:%s is:pr author:%s updated:>=%s", repo, c.username, from.UTC().Format("2006-01-02"))
+
+	var cursor interface{}
+	for page := 0; page < maxPages; page++ {
+		var result struct {
+			Search struct {
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+				Nodes []struct {
+					Number      int    `json:"number"`
+					HeadRefName string `json:"headRefName"`
+					Commits     struct {
+						Nodes []struct {
+							Commit graphCommit `json:"commit"`
+						} `json:"nodes"`
+					} `json:"commits"`
+				} `json:"nodes"`
+			} `json:"search"`
+		}
+
+		variables := map[string]interface{}{
+			"search": search,
+			"cursor": cursor,
+		}
+		if err := c.graphql(ctx, pullRequestCommitsQuery, variables, &result); err != nil {
+			return err
+		}
+
+		for _, pr := range result.Search.Nodes {
+			for _, node := range pr.Commits.Nodes {
+				if between(node.Commit.AuthoredDate, from, to) {
+					collector.add(node.Commit, pr.HeadRefName, pr.Number)
+				}
+			}
+		}
+
+		if !result.Search.PageInfo.HasNextPage {
+			break
+		}
+		cursor = result.Search.PageInfo.EndCursor
+	}
+
+	return nil
+}
+
+// between reports whether t falls in [from, to).
+func between(t, from, to time.Time) bool {
+	return !t.Before(from) && t.Before(to)
+}
+
+func (c *Client) getDefaultBranch(ctx context.Context, owner, name string) (string, error) {
+	var repo struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := c.getCached(ctx, fmt.Sprintf("repos/%s/%s", url.PathEscape(owner), url.PathEscape(name)), nil, defaultBranchTTL, &repo); err != nil {
+		return "", err
+	}
+
+	if repo.DefaultBranch == "" {
+		return "main", nil
+	}
+	return repo.DefaultBranch, nil
+}
+
+// commitCollector deduplicates commits seen on several branches and PRs,
+// keeping the most specific branch and any PR number.
+type commitCollector struct {
+	client        *Client
+	repo          string
+	defaultBranch string
+	order         []string
+	bySHA         map[string]*Commit
+}
+
+func newCommitCollector(c *Client, repo string) *commitCollector {
+	return &commitCollector{
+		client: c,
+		repo:   repo,
+		bySHA:  make(map[string]*Commit),
+	}
+}
+
+func (cc *commitCollector) add(node graphCommit, branch string, prNumber int) {
+	// Filter by author, or co-author, to only include current user's commits
+	login := ""
+	if node.Author.User != nil {
+		login = node.Author.User.Login
+	}
+	if !cc.client.identity.MatchesCommit(node.Author.Name, node.Author.Email, login, node.Message) {
+		return
+	}
+
+	if prNumber == 0 && len(node.AssociatedPullRequests.Nodes) > 0 {
+		prNumber = node.AssociatedPullRequests.Nodes[0].Number
+	}
+
+	if existing, ok := cc.bySHA[node.OID]; ok {
+		// A feature branch says more about the work than the branch it
+		// was merged into
+		if existing.Branch == "" || existing.Branch == cc.defaultBranch {
+			existing.Branch = branch
+		}
+		if existing.PullRequest == 0 {
+			existing.PullRequest = prNumber
+		}
+		return
+	}
+
+	// GitHub leaves the file count out for very large commits
+	files := 0
+	if node.ChangedFiles != nil {
+		files = *node.ChangedFiles
+	}
+
+	cc.order = append(cc.order, node.OID)
+	cc.bySHA[node.OID] = &Commit{
+		SHA:          node.OID,
+		Message:      node.Message,
+		URL:          node.URL,
+		Repository:   cc.repo,
+		AuthorDate:   node.AuthoredDate,
+		Branch:       branch,
+		PullRequest:  prNumber,
+		Additions:    node.Additions,
+		Deletions:    node.Deletions,
+		FilesChanged: files,
+	}
+}
+
+func (cc *commitCollector) commits() []Commit {
+	var commits []Commit
+	for _, sha := range cc.order {
+		commits = append(commits, *cc.bySHA[sha])
+	}
+	return commits
+}
@@ -0,0 +1,238 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The events feed only ever returns the 300 most recent events.
+const maxEvents = 300
+
+type repository struct {
+	FullName string    `json:"full_name"`
+	PushedAt time.Time `json:"pushed_at"`
+}
+
+// getRecentRepositories returns every repository the user may have worked
+// in since the given time, filtered through the include/exclude lists.
+// Results are memoised per since so commits and PRs share one discovery.
+func (c *Client) getRecentRepositories(ctx context.Context, since time.Time) ([]string, error) {
+	key := since.UTC().Format(time.RFC3339)
+
+	c.discoveryMu.Lock()
+	defer c.discoveryMu.Unlock()
+
+	if repos, ok := c.discovered[key]; ok {
+		return repos, nil
+	}
+
+	// Keyed case-insensitively since search and events disagree on casing
+	repoMap := make(map[string]string)
+	add := func(name string) {
+		if name != "" && c.allowRepository(name) {
+			repoMap[strings.ToLower(name)] = name
+		}
+	}
+
+	// The authenticated events feed includes private repositories, unlike
+	// the public one
+	events, err := c.getEventRepositories(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read events feed: %v", err)
+	}
+	for _, repo := range events {
+		add(repo)
+	}
+
+	// Repos the user owns, collaborates on or can see through an org, most
+	// recently pushed first
+	pushed, err := c.getPushedRepositories(ctx, "user/repos", url.Values{"affiliation": {"owner,collaborator,organization_member"}}, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %v", err)
+	}
+	for _, repo := range pushed {
+		add(repo)
+	}
+
+	// Org repos the token can see but the user isn't a direct member of
+	orgs, err := c.getOrganizations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %v", err)
+	}
+	for _, org := range orgs {
+		if !c.allowOwner(org) {
+			continue
+		}
+		repos, err := c.getPushedRepositories(ctx, fmt.Sprintf("orgs/%s/repos", url.PathEscape(org)), url.Values{"type": {"all"}}, since)
+		if err != nil {
+			// SSO-protected orgs refuse tokens that haven't been authorised
+			if IsForbidden(err) || IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to list repositories for %s: %v", org, err)
+		}
+		for _, repo := range repos {
+			add(repo)
+		}
+	}
+
+	// Commit search catches repos that fell off the events feed
+	searched, err := c.searchCommitRepositories(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search commits: %v", err)
+	}
+	for _, repo := range searched {
+		add(repo)
+	}
+
+	var repoNames []string
+	for _, repo := range repoMap {
+		repoNames = append(repoNames, repo)
+	}
+	sort.Strings(repoNames)
+
+	c.discovered[key] = repoNames
+	return repoNames, nil
+}
+
+func (c *Client) getEventRepositories(ctx context.Context, since time.Time) ([]string, error) {
+	var repos []string
+	seen := 0
+
+	err := getAllPages(ctx, c, fmt.Sprintf("users/%s/events", url.PathEscape(c.username)), nil, eventsTTL, func(event struct {
+		Repo struct {
+			Name string `json:"name"`
+		} `json:"repo"`
+		CreatedAt time.Time `json:"created_at"`
+	}) bool {
+		seen++
+		if event.CreatedAt.Before(since) {
+			return false
+		}
+		repos = append(repos, event.Repo.Name)
+		return seen < maxEvents
+	})
+
+	return repos, err
+}
+
+// getPushedRepositories lists repositories pushed to since the given time.
+// The lists are cached for repoListTTL; pushes since then still turn up in
+// the events feed and commit search.
+func (c *Client) getPushedRepositories(ctx context.Context, path string, params url.Values, since time.Time) ([]string, error) {
+	query := url.Values{"sort": {"pushed"}, "direction": {"desc"}}
+	for key, values := range params {
+		query[key] = values
+	}
+
+	var repos []string
+	err := getAllPages(ctx, c, path, query, repoListTTL, func(repo repository) bool {
+		if repo.PushedAt.Before(since) {
+			return false
+		}
+		repos = append(repos, repo.FullName)
+		return true
+	})
+
+	return repos, err
+}
+
+func (c *Client) getOrganizations(ctx context.Context) ([]string, error) {
+	var orgs []string
+	err := getAllPages(ctx, c, "user/orgs", nil, repoListTTL, func(org struct {
+		Login string `json:"login"`
+	}) bool {
+		orgs = append(orgs, org.Login)
+		return true
+	})
+
+	return orgs, err
+}
+
+func (c *Client) searchCommitRepositories(ctx context.Context, since time.Time) ([]string, error) {
+	query := fmt.Sprintf("author:%s author-date:>=%s", c.username, since.Format("2006-01-02"))
+
+	var repos []string
+	err := searchAll(ctx, c, "search/commits", query, func(item struct {
+		Repository repository `json:"repository"`
+	}) bool {
+		repos = append(repos, item.Repository.FullName)
+		return true
+	})
+
+	return repos, err
+}
+
+// allowRepository applies the include/exclude lists to an owner/name pair.
+// Entries without a slash match every repository of that owner. Exclusions
+// win over inclusions, and an empty include list allows everything.
+func (c *Client) allowRepository(fullName string) bool {
+	owner, _, _ := strings.Cut(fullName, "/")
+
+	if c.matchesRepoList(c.exclude, owner, fullName) {
+		return false
+	}
+	if len(c.include) == 0 {
+		return true
+	}
+	return c.matchesRepoList(c.include, owner, fullName)
+}
+
+// allowOwner reports whether any repository of owner could pass the
+// filters, so whole orgs can be skipped without listing them.
+func (c *Client) allowOwner(owner string) bool {
+	for _, entry := range c.exclude {
+		if entry, ok := c.localEntry(entry); ok && strings.EqualFold(entry, owner) {
+			return false
+		}
+	}
+	if len(c.include) == 0 {
+		return true
+	}
+	for _, entry := range c.include {
+		entry, ok := c.localEntry(entry)
+		if !ok {
+			continue
+		}
+		entryOwner, _, _ := strings.Cut(entry, "/")
+		if strings.EqualFold(entryOwner, owner) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) matchesRepoList(list []string, owner, fullName string) bool {
+	for _, entry := range list {
+		entry, ok := c.localEntry(entry)
+		if !ok {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			if strings.EqualFold(entry, fullName) {
+				return true
+			}
+		} else if strings.EqualFold(entry, owner) {
+			return true
+		}
+	}
+	return false
+}
+
+// localEntry strips a host qualifier ("github.acme.com/acme/web") from a
+// filter entry. Entries for other hosts report false. GitHub logins can't
+// contain dots, so a dotted first segment is always a hostname.
+func (c *Client) localEntry(entry string) (string, bool) {
+	first, rest, found := strings.Cut(entry, "/")
+	if !found || !strings.Contains(first, ".") {
+		return entry, true
+	}
+	if !strings.EqualFold(first, c.host) {
+		return "", false
+	}
+	return rest, true
+}
@@ -0,0 +1,72 @@
+package github
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Identity is every name, email and login that counts as the user when
+// deciding whether they wrote a commit. Commits made from a work email or
+// an account that isn't linked to GitHub only match through Names and
+// Emails.
+type Identity struct {
+	Logins []string
+	Names  []string
+	Emails []string
+}
+
+var coAuthorPattern = regexp.MustCompile(`(?im)^co-authored-by:\s*(.*?)\s*<([^>]+)>\s*$`)
+
+// Matches reports whether an author with the given name, email and GitHub
+// login (any of which may be empty) is the user. GitHub noreply addresses
+// are matched against the logins.
+func (id Identity) Matches(name, email, login string) bool {
+	if login != "" && containsFold(id.Logins, login) {
+		return true
+	}
+	if name != "" && (containsFold(id.Names, name) || containsFold(id.Logins, name)) {
+		return true
+	}
+	if email == "" {
+		return false
+	}
+	if containsFold(id.Emails, email) {
+		return true
+	}
+
+	local, domain, ok := strings.Cut(strings.ToLower(email), "@")
+	if ok && domain == "users.noreply.github.com" {
+		// Either login@ or 12345+login@
+		if _, after, found := strings.Cut(local, "+"); found {
+			local = after
+		}
+		return containsFold(id.Logins, local)
+	}
+
+	return false
+}
+
+// MatchesCommit is Matches for the commit author, falling back to any
+// Co-authored-by trailers in the message.
+func (id Identity) MatchesCommit(name, email, login, message string) bool {
+	if id.Matches(name, email, login) {
+		return true
+	}
+
+	for _, match := range coAuthorPattern.FindAllStringSubmatch(message, -1) {
+		if id.Matches(match[1], match[2], "") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
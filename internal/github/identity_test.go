@@ -0,0 +1,68 @@
+package github
+
+import "testing"
+
+func TestIdentityMatchesCommit(t *testing.T) {
+	id := Identity{
+		Logins: []string{"octocat"},
+		Names:  []string{"Mona Lisa"},
+		Emails: []string{"mona@work.example.com", "mona@home.example.com"},
+	}
+
+	tests := []struct {
+		name                          string
+		author, email, login, message string
+		want                          bool
+	}{
+		{name: "login", author: "Someone", email: "someone@example.com", login: "Octocat", want: true},
+		{name: "name", author: "mona lisa", email: "someone@example.com", want: true},
+		{name: "first extra email", author: "M", email: "mona@work.example.com", want: true},
+		{name: "second extra email", author: "M", email: "MONA@home.example.com", want: true},
+		{name: "noreply address", email: "octocat@users.noreply.github.com", want: true},
+		{name: "noreply address with id", email: "12345+octocat@users.noreply.github.com", want: true},
+		{name: "noreply address for someone else", email: "12345+hubot@users.noreply.github.com", want: false},
+		{name: "someone else", author: "Hubot", email: "hubot@example.com", login: "hubot", want: false},
+		{
+			name:    "co-author by email",
+			author:  "Hubot",
+			email:   "hubot@example.com",
+			message: "Add search\n\nCo-authored-by: M <mona@home.example.com>",
+			want:    true,
+		},
+		{
+			name:    "co-author by name",
+			author:  "Hubot",
+			email:   "hubot@example.com",
+			message: "Add search\n\nco-authored-by: Mona Lisa <other@example.com>",
+			want:    true,
+		},
+		{
+			name:    "co-author by noreply address",
+			author:  "Hubot",
+			email:   "hubot@example.com",
+			message: "Add search\n\nCo-authored-by: Hubot <hubot@example.com>\nCo-authored-by: Octo <1+octocat@users.noreply.github.com>",
+			want:    true,
+		},
+		{
+			name:    "other co-authors",
+			author:  "Hubot",
+			email:   "hubot@example.com",
+			message: "Add search\n\nCo-authored-by: Jane <jane@example.com>",
+			want:    false,
+		},
+		{
+			name:    "trailer must start a line",
+			author:  "Hubot",
+			email:   "hubot@example.com",
+			message: "Add search, see Co-authored-by: M <mona@work.example.com>",
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := id.MatchesCommit(tt.author, tt.email, tt.login, tt.message); got != tt.want {
+				t.Errorf("MatchesCommit = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
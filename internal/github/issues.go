@@ -0,0 +1,145 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// IssueActivity is one thing the user did on an issue: opening, commenting
+// on, labeling, closing or reopening it.
+type IssueActivity struct {
+	Repository string
+	Number     int
+	Title      string
+	URL        string
+	Action     string // opened, commented, labeled, unlabeled, closed, reopened
+	Detail     string // label name or comment body
+	At         time.Time
+}
+
+// Timeline events worth counting as the user's own issue work
+var issueTimelineActions = map[string]bool{
+	"commented": true,
+	"labeled":   true,
+	"unlabeled": true,
+	"closed":    true,
+	"reopened":  true,
+}
+
+// GetIssueActivity returns what the user did on issues between since and
+// until, oldest first.
+func (c *Client) GetIssueActivity(ctx context.Context, since, until time.Time) ([]IssueActivity, error) {
+	query := fmt.Sprintf("type:issue involves:%s updated:>=%s", c.username, since.Format("2006-01-02"))
+
+	issues := make(map[string]searchIssue)
+	var refs []string
+	err := searchAll(ctx, c, "search/issues", query, func(issue searchIssue) bool {
+		repo := issue.repository()
+		if !c.allowRepository(repo) {
+			return true
+		}
+		ref := fmt.Sprintf("%s#%d", repo, issue.Number)
+		issues[ref] = issue
+		refs = append(refs, ref)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search issues: %v", err)
+	}
+
+	var (
+		mu          sync.Mutex
+		allActivity []IssueActivity
+	)
+
+	err = c.forEachRepo(ctx, "issues", refs, func(ctx context.Context, ref string) error {
+		activity, err := c.getIssueActivity(ctx, issues[ref], since, until)
+		if err != nil {
+			if IsNotFound(err) || IsForbidden(err) {
+				return nil
+			}
+			if IsRateLimited(err) {
+				return err
+			}
+			return fmt.Errorf("failed to get timeline for %s: %v", ref, err)
+		}
+
+		mu.Lock()
+		allActivity = append(allActivity, activity...)
+		mu.Unlock()
+		return nil
+	})
+
+	sort.Slice(allActivity, func(i, j int) bool { return allActivity[i].At.Before(allActivity[j].At) })
+	return allActivity, err
+}
+
+func (c *Client) getIssueActivity(ctx context.Context, issue searchIssue, since, until time.Time) ([]IssueActivity, error) {
+	repo := issue.repository()
+	inRange := func(t time.Time) bool {
+		return !t.Before(since) && t.Before(until)
+	}
+
+	newActivity := func(action, detail string, at time.Time) IssueActivity {
+		return IssueActivity{
+			Repository: c.repoID(repo),
+			Number:     issue.Number,
+			Title:      issue.Title,
+			URL:        issue.HTMLURL,
+			Action:     action,
+			Detail:     detail,
+			At:         at,
+		}
+	}
+
+	var activity []IssueActivity
+	if issue.User.Login == c.username && inRange(issue.CreatedAt) {
+		activity = append(activity, newActivity("opened", "", issue.CreatedAt))
+	}
+
+	path := fmt.Sprintf("repos/%s/issues/%d/timeline", repo, issue.Number)
+	err := getAllPages(ctx, c, path, nil, noCache, func(event struct {
+		Event     string    `json:"event"`
+		Body      string    `json:"body"`
+		CreatedAt time.Time `json:"created_at"`
+		Actor     *struct {
+			Login string `json:"login"`
+		} `json:"actor"`
+		User *struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		Label *struct {
+			Name string `json:"name"`
+		} `json:"label"`
+	}) bool {
+		if !issueTimelineActions[event.Event] || !inRange(event.CreatedAt) {
+			return true
+		}
+
+		// Comments carry the author in user, other events in actor
+		login := ""
+		if event.Actor != nil {
+			login = event.Actor.Login
+		} else if event.User != nil {
+			login = event.User.Login
+		}
+		if login != c.username {
+			return true
+		}
+
+		detail := event.Body
+		if event.Label != nil {
+			detail = event.Label.Name
+		}
+		activity = append(activity, newActivity(event.Event, detail, event.CreatedAt))
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return activity, nil
+}
@@ -1,22 +1,73 @@
 package github
 
 import (
+	"context"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/digitaldrywood/timetracker/internal/activity"
+)
+
+const (
+	// DefaultLocalMaxDepth is how many directories below a root are
+	// searched for clones.
+	DefaultLocalMaxDepth = 4
+
+	// DefaultLocalRescanInterval is how long discovered clones are reused
+	// before the roots are walked again.
+	DefaultLocalRescanInterval = 24 * time.Hour
 )
 
-// GetLocalCommits finds commits in local git repositories
-func (c *Client) GetLocalCommits(basePaths []string, since time.Time) ([]Commit, error) {
-	var allCommits []Commit
-	
-	// Default paths to check if none provided
-	if len(basePaths) == 0 {
-		home, _ := os.UserHomeDir()
-		basePaths = []string{
+// Directories never worth descending into
+var defaultLocalExcludes = []string{"node_modules", "vendor", ".cache"}
+
+// LocalRepoCache stores the clones found under each root so the roots
+// aren't walked on every run. *database.DB implements it.
+type LocalRepoCache interface {
+	GetLocalRepos(scanKey string) ([]string, time.Time, error)
+	PutLocalRepos(scanKey string, paths []string, scannedAt time.Time) error
+}
+
+// LocalOptions configures where a LocalCollector looks for clones.
+type LocalOptions struct {
+	// Roots are the directories searched for clones; a leading ~ is
+	// expanded. Empty means a few common project directories in $HOME.
+	Roots []string
+
+	// Exclude lists directory names or glob patterns to skip, on top of
+	// node_modules, vendor and .cache. Patterns containing a slash are
+	// matched against the full path.
+	Exclude []string
+
+	MaxDepth       int
+	RescanInterval time.Duration
+	Concurrency    int
+	Cache          LocalRepoCache
+}
+
+// LocalCollector finds the user's commits in git repositories cloned under
+// a set of root directories, including ones not pushed anywhere yet.
+type LocalCollector struct {
+	identity Identity
+	opts     LocalOptions
+
+	mu    sync.Mutex
+	repos []localRepo
+}
+
+func NewLocalCollector(identity Identity, opts LocalOptions) *LocalCollector {
+	home, _ := os.UserHomeDir()
+	roots := opts.Roots
+	if len(roots) == 0 {
+		roots = []string{
 			filepath.Join(home, "projects"),
 			filepath.Join(home, "code"),
 			filepath.Join(home, "dev"),
@@ -25,121 +76,477 @@ func (c *Client) GetLocalCommits(basePaths []string, since time.Time) ([]Commit,
 			filepath.Join(home, "repos"),
 		}
 	}
-	
-	// Find all git repositories
-	gitRepos := []string{}
-	for _, basePath := range basePaths {
-		if _, err := os.Stat(basePath); os.IsNotExist(err) {
-			continue
+
+	opts.Roots = make([]string, len(roots))
+	for i, root := range roots {
+		if root == "~" || strings.HasPrefix(root, "~/") {
+			root = filepath.Join(home, root[1:])
 		}
-		
-		err := filepath.Walk(basePath, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return nil // Skip errors
-			}
-			
-			// Check if this is a .git directory
-			if info.IsDir() && info.Name() == ".git" {
-				repoPath := filepath.Dir(path)
-				gitRepos = append(gitRepos, repoPath)
-				return filepath.SkipDir // Don't recurse into .git
-			}
-			
-			// Skip common non-project directories
-			if info.IsDir() && (info.Name() == "node_modules" || info.Name() == "vendor" || info.Name() == ".cache") {
-				return filepath.SkipDir
-			}
-			
-			return nil
-		})
-		
+		opts.Roots[i] = filepath.Clean(root)
+	}
+
+	opts.Exclude = append(append([]string{}, defaultLocalExcludes...), opts.Exclude...)
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = DefaultLocalMaxDepth
+	}
+	if opts.RescanInterval <= 0 {
+		opts.RescanInterval = DefaultLocalRescanInterval
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+
+	return &LocalCollector{identity: identity, opts: opts}
+}
+
+// AddLogins adds the API logins of the configured hosts, since a local
+// commit may be authored under any of them.
+func (l *LocalCollector) AddLogins(logins ...string) {
+	for _, login := range logins {
+		if login != "" && !containsFold(l.identity.Logins, login) {
+			l.identity.Logins = append(l.identity.Logins, login)
+		}
+	}
+}
+
+func (l *LocalCollector) Name() string {
+	return "local"
+}
+
+func (l *LocalCollector) Fetch(ctx context.Context, from, to time.Time) ([]activity.Item, error) {
+	commits, err := l.GetCommits(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	wip, err := l.GetWorkInProgress(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []activity.Item
+	for _, commit := range commits {
+		item := CommitItem(l.Name(), commit)
+		item.Local = true
+		items = append(items, item)
+	}
+	for _, w := range wip {
+		items = append(items, workInProgressItem(l.Name(), w))
+	}
+	return items, nil
+}
+
+func workInProgressItem(source string, wip WorkInProgress) activity.Item {
+	item := activity.Item{
+		Source:       source,
+		Kind:         activity.KindWorkInProgress,
+		Repository:   wip.Repository,
+		Time:         wip.Start,
+		End:          wip.End,
+		Title:        fmt.Sprintf("%d uncommitted files", len(wip.Files)),
+		Body:         strings.Join(wip.Files, "\n"),
+		URL:          "file://" + wip.Path,
+		Branch:       wip.Branch,
+		Path:         wip.Path,
+		State:        "changes",
+		Local:        true,
+		Additions:    wip.Additions,
+		Deletions:    wip.Deletions,
+		FilesChanged: len(wip.Files),
+	}
+	if wip.Stash != "" {
+		item.Title = wip.Stash
+		item.State = "stash"
+	}
+	return item
+}
+
+// GetCommits finds commits authored between from and to in local git
+// repositories.
+func (l *LocalCollector) GetCommits(ctx context.Context, from, to time.Time) ([]Commit, error) {
+	var (
+		mu         sync.Mutex
+		allCommits []Commit
+	)
+
+	clones, err := l.clones(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = l.forEachRepo(ctx, clones, func(repo localRepo) {
+		commits, err := l.getRepoCommits(ctx, repo, from, to)
 		if err != nil {
+			return
+		}
+
+		mu.Lock()
+		allCommits = append(allCommits, commits...)
+		mu.Unlock()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the output stable regardless of which git finished first
+	sort.SliceStable(allCommits, func(i, j int) bool { return allCommits[i].AuthorDate.Before(allCommits[j].AuthorDate) })
+	return allCommits, nil
+}
+
+// LocalRepository is a working tree found under the collector's roots.
+type LocalRepository struct {
+	Path string
+	Name string // host/owner/repo identity, the parent's for submodules
+}
+
+// RescanInterval is how long discovered clones are reused before the roots
+// are walked again.
+func (l *LocalCollector) RescanInterval() time.Duration {
+	return l.opts.RescanInterval
+}
+
+// Repositories rescans the roots for working trees, bypassing the scan
+// cache, for long-running callers such as the daemon.
+func (l *LocalCollector) Repositories(ctx context.Context) ([]LocalRepository, error) {
+	repos, err := l.find(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []LocalRepository
+	for _, repo := range repos {
+		result = append(result, LocalRepository{Path: repo.path, Name: repo.name})
+	}
+	return result, nil
+}
+
+// localRepo is a working tree found under one of the roots.
+type localRepo struct {
+	path   string
+	gitDir string // common git directory, shared by a clone's worktrees
+	name   string // repository identity; submodules take their parent's
+}
+
+// forEachRepo runs fn for each repo, several at a time. Errors are fn's to
+// handle; a clone that git can't read is skipped.
+func (l *LocalCollector) forEachRepo(ctx context.Context, repos []localRepo, fn func(repo localRepo)) error {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, l.opts.Concurrency)
+
+	for _, repo := range repos {
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(repo localRepo) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(repo)
+		}(repo)
+	}
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// clones returns one working tree per git directory, so a clone with
+// linked worktrees has its history read once. The main worktree is
+// preferred.
+func (l *LocalCollector) clones(ctx context.Context) ([]localRepo, error) {
+	repos, err := l.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var clones []localRepo
+	index := make(map[string]int)
+	for _, repo := range repos {
+		i, seen := index[repo.gitDir]
+		if !seen {
+			index[repo.gitDir] = len(clones)
+			clones = append(clones, repo)
 			continue
 		}
+		if repo.gitDir == filepath.Join(repo.path, ".git") {
+			clones[i] = repo
+		}
+	}
+	return clones, nil
+}
+
+// discover returns every working tree under the roots, including linked
+// worktrees and submodules, looking them up once per collector.
+func (l *LocalCollector) discover(ctx context.Context) ([]localRepo, error) {
+	return l.find(ctx, false)
+}
+
+// find looks up the working trees, rescanning the roots when rescan is set
+// even if the scan cache is fresh.
+func (l *LocalCollector) find(ctx context.Context, rescan bool) ([]localRepo, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.repos != nil && !rescan {
+		return l.repos, nil
 	}
-	
-	// Get commits from each repo
-	sinceStr := since.Format("2006-01-02")
-	for _, repoPath := range gitRepos {
-		commits, err := c.getLocalRepoCommits(repoPath, sinceStr)
+
+	var paths []string
+	seen := make(map[string]bool)
+	for _, root := range l.opts.Roots {
+		found, err := l.getRepos(root, rescan)
 		if err != nil {
+			return nil, err
+		}
+		// Nested roots find the same clones
+		for _, path := range found {
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+
+	repos := []localRepo{}
+	superprojects := make(map[string]string)
+	for _, path := range paths {
+		output := git(ctx, path, "rev-parse", "--path-format=absolute", "--git-common-dir", "--show-superproject-working-tree")
+		lines := strings.Split(strings.TrimSpace(output), "\n")
+		if lines[0] == "" {
 			continue
 		}
-		allCommits = append(allCommits, commits...)
+		if len(lines) > 1 {
+			superprojects[path] = lines[1]
+		}
+		repos = append(repos, localRepo{path: path, gitDir: filepath.Clean(lines[0])})
 	}
-	
-	return allCommits, nil
+
+	// Submodule commits belong to the project that includes them
+	names := make(map[string]string)
+	var nameOf func(path string, hops int) string
+	nameOf = func(path string, hops int) string {
+		if super, ok := superprojects[path]; ok && hops < 10 {
+			return nameOf(super, hops+1)
+		}
+		if name, ok := names[path]; ok {
+			return name
+		}
+		names[path] = localRepoName(ctx, path)
+		return names[path]
+	}
+	for i := range repos {
+		repos[i].name = nameOf(repos[i].path, 0)
+	}
+
+	l.repos = repos
+	return repos, nil
 }
 
-func (c *Client) getLocalRepoCommits(repoPath string, since string) ([]Commit, error) {
-	// Get the repository name from the path
-	repoName := filepath.Base(repoPath)
-	
-	// Try to get remote URL to determine full repo name
-	cmd := exec.Command("git", "remote", "get-url", "origin")
+// getRepos returns the clones under root, from the cache while it is
+// fresher than the rescan interval unless rescan is set.
+func (l *LocalCollector) getRepos(root string, rescan bool) ([]string, error) {
+	key := fmt.Sprintf("%s?depth=%d&exclude=%s", root, l.opts.MaxDepth, strings.Join(l.opts.Exclude, ","))
+
+	if l.opts.Cache != nil && !rescan {
+		repos, scannedAt, err := l.opts.Cache.GetLocalRepos(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read local repository cache: %v", err)
+		}
+		if !scannedAt.IsZero() && time.Since(scannedAt) < l.opts.RescanInterval {
+			return repos, nil
+		}
+	}
+
+	repos := l.scan(root)
+
+	if l.opts.Cache != nil {
+		if err := l.opts.Cache.PutLocalRepos(key, repos, time.Now()); err != nil {
+			return nil, fmt.Errorf("failed to write local repository cache: %v", err)
+		}
+	}
+
+	return repos, nil
+}
+
+// scan walks root for git clones, down to MaxDepth directories.
+func (l *LocalCollector) scan(root string) []string {
+	var repos []string
+	if _, err := os.Stat(root); err != nil {
+		return nil
+	}
+
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip unreadable directories
+		}
+
+		// A .git directory marks a clone; a .git file a linked worktree or
+		// a submodule whose git directory lives in its parent
+		if d.Name() == ".git" {
+			repos = append(repos, filepath.Dir(path))
+			if d.IsDir() {
+				return filepath.SkipDir // Don't recurse into .git
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+
+		if path != root && (l.excluded(path, d.Name()) || depth(root, path) > l.opts.MaxDepth) {
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+
+	return repos
+}
+
+func (l *LocalCollector) excluded(path, name string) bool {
+	for _, pattern := range l.opts.Exclude {
+		target := name
+		if strings.Contains(pattern, "/") {
+			target = path
+		}
+		if ok, _ := filepath.Match(pattern, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// depth counts the directories between root and path.
+func depth(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
+
+// localRepoName names a clone host/owner/repo after its origin remote, the
+// same as the API clients do, or after its directory when it has none.
+func localRepoName(ctx context.Context, repoPath string) string {
+	cmd := exec.CommandContext(ctx, "git", "remote", "get-url", "origin")
 	cmd.Dir = repoPath
 	output, err := cmd.Output()
 	if err == nil {
-		url := strings.TrimSpace(string(output))
-		// Parse GitHub URL to get owner/repo
-		if strings.Contains(url, "github.com") {
-			parts := strings.Split(url, "github.com")
-			if len(parts) > 1 {
-				repoPath := strings.TrimPrefix(parts[1], ":")
-				repoPath = strings.TrimPrefix(repoPath, "/")
-				repoPath = strings.TrimSuffix(repoPath, ".git")
-				if repoPath != "" {
-					repoName = repoPath
-				}
-			}
+		if id, ok := ParseRemoteURL(string(output)); ok {
+			return id
 		}
 	}
-	
-	// Get commits using git log
-	cmd = exec.Command("git", "log", 
-		"--all",  // Check all branches
-		"--since="+since,
-		"--author="+c.username,
-		"--pretty=format:%H|%s|%aI",
+	return filepath.Base(repoPath)
+}
+
+func (l *LocalCollector) getRepoCommits(ctx context.Context, repo localRepo, from, to time.Time) ([]Commit, error) {
+	repoPath := repo.path
+
+	// Get commits using git log. Authors are matched afterwards so work
+	// emails and Co-authored-by trailers count too. Each record starts
+	// with \x1e and its --numstat lines follow the \x1d after the body.
+	cmd := exec.CommandContext(ctx, "git", "log",
+		"--exclude=refs/stash", // Stashes are reported as work in progress
+		"--all",                // Check all branches
+		"--since="+from.Add(-commitDateSlack).Format(time.RFC3339),
+		"--source", // Record the branch each commit was reached from
+		"--pretty=format:%x1e%H%x1f%S%x1f%aI%x1f%an%x1f%ae%x1f%s%x1f%b%x1d",
+		"--numstat",
 		"--no-merges")
 	cmd.Dir = repoPath
-	
-	output, err = cmd.Output()
+
+	output, err := cmd.Output()
 	if err != nil || len(output) == 0 {
 		return nil, nil
 	}
-	
+
+	pushed := l.getPushedCommits(ctx, repoPath, from)
+
 	var commits []Commit
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if line == "" {
+	records := strings.Split(string(output), "\x1e")
+	for _, record := range records {
+		record, numstat, _ := strings.Cut(record, "\x1d")
+		if record == "" {
 			continue
 		}
-		
-		parts := strings.SplitN(line, "|", 3)
-		if len(parts) != 3 {
+
+		parts := strings.SplitN(record, "\x1f", 7)
+		if len(parts) != 7 {
 			continue
 		}
-		
+
 		authorDate, err := time.Parse(time.RFC3339, parts[2])
 		if err != nil {
 			continue
 		}
-		
+
+		// --since filters on committer date; entries follow the author
+		// date
+		if authorDate.Before(from) || !authorDate.Before(to) {
+			continue
+		}
+
+		if !l.identity.MatchesCommit(parts[3], parts[4], "", parts[6]) {
+			continue
+		}
+
+		additions, deletions, files := parseNumstat(numstat)
 		commits = append(commits, Commit{
-			SHA:        parts[0][:7], // Short SHA
-			Message:    parts[1],
-			URL:        fmt.Sprintf("file://%s/commit/%s", repoPath, parts[0]),
-			Repository: repoName + " (local)",
-			AuthorDate: authorDate,
+			SHA:          parts[0],
+			Message:      parts[5],
+			URL:          fmt.Sprintf("file://%s/commit/%s", repoPath, parts[0]),
+			Repository:   repo.name,
+			AuthorDate:   authorDate,
+			Branch:       strings.TrimPrefix(strings.TrimPrefix(parts[1], "refs/heads/"), "refs/remotes/"),
+			Unpushed:     !pushed[parts[0]],
+			Path:         repoPath,
+			Additions:    additions,
+			Deletions:    deletions,
+			FilesChanged: files,
 		})
 	}
-	
+
 	return commits, nil
 }
 
-// GetTodayLocalCommits gets local commits from today
-func (c *Client) GetTodayLocalCommits() ([]Commit, error) {
-	today := time.Now().Truncate(24 * time.Hour)
-	return c.GetLocalCommits(nil, today)
-}
\ No newline at end of file
+// parseNumstat totals `git log --numstat` lines ("added\tdeleted\tpath").
+// Binary files show "-" for both counts and only add to the file count.
+func parseNumstat(numstat string) (additions, deletions, files int) {
+	for _, line := range strings.Split(numstat, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		files++
+		if n, err := strconv.Atoi(fields[0]); err == nil {
+			additions += n
+		}
+		if n, err := strconv.Atoi(fields[1]); err == nil {
+			deletions += n
+		}
+	}
+	return additions, deletions, files
+}
+
+// getPushedCommits returns the SHAs committed since the window opened that
+// are reachable from a remote-tracking branch, i.e. already pushed
+// somewhere.
+func (l *LocalCollector) getPushedCommits(ctx context.Context, repoPath string, from time.Time) map[string]bool {
+	cmd := exec.CommandContext(ctx, "git", "log",
+		"--remotes",
+		"--since="+from.Add(-commitDateSlack).Format(time.RFC3339),
+		"--pretty=format:%H",
+		"--no-merges")
+	cmd.Dir = repoPath
+
+	pushed := make(map[string]bool)
+	output, err := cmd.Output()
+	if err != nil {
+		return pushed
+	}
+	for _, sha := range strings.Fields(string(output)) {
+		pushed[sha] = true
+	}
+	return pushed
+}
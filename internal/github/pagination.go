@@ -0,0 +1,71 @@
+package github
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	perPage = 100
+
+	// maxPages guards against runaway pagination. The events feed stops at
+	// 300 items, so nothing legitimate needs more.
+	maxPages = 20
+
+	// Search returns at most 1000 results and answers 422 past them
+	maxSearchPages = 1000 / perPage
+)
+
+// getAllPages walks a REST list endpoint page by page. keep is called for
+// each item in order and returning false stops pagination, which lets
+// callers bail out once a pushed_at or created_at sorted list passes the
+// window they care about.
+func getAllPages[T any](ctx context.Context, c *Client, path string, params url.Values, ttl time.Duration, keep func(T) bool) error {
+	return walkPages(ctx, c, path, params, ttl, maxPages, func(page *[]T) []T { return *page }, keep)
+}
+
+// searchAll walks a search endpoint, whose results are wrapped in an object
+// rather than returned as a bare array. It stops quietly at the 1000 results
+// search will return.
+func searchAll[T any](ctx context.Context, c *Client, path string, query string, keep func(T) bool) error {
+	params := url.Values{"q": {query}}
+	return walkPages(ctx, c, path, params, noCache, maxSearchPages, func(page *searchPage[T]) []T { return page.Items }, keep)
+}
+
+type searchPage[T any] struct {
+	TotalCount int `json:"total_count"`
+	Items      []T `json:"items"`
+}
+
+func walkPages[P any, T any](ctx context.Context, c *Client, path string, params url.Values, ttl time.Duration, limit int, items func(*P) []T, keep func(T) bool) error {
+	query := url.Values{}
+	for key, values := range params {
+		query[key] = values
+	}
+	query.Set("per_page", strconv.Itoa(perPage))
+
+	for page := 1; page <= limit; page++ {
+		query.Set("page", strconv.Itoa(page))
+
+		var body P
+		if err := c.getCached(ctx, path, query, ttl, &body); err != nil {
+			return err
+		}
+
+		results := items(&body)
+
+		for _, item := range results {
+			if !keep(item) {
+				return nil
+			}
+		}
+
+		if len(results) < perPage {
+			return nil
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestGetAllPages(t *testing.T) {
+	const total = 2*perPage + 10
+
+	tests := []struct {
+		name      string
+		stopAfter int // keep returns false after this many items; 0 keeps all
+		wantItems int
+		wantPages []string
+	}{
+		{name: "all pages", wantItems: total, wantPages: []string{"1", "2", "3"}},
+		{name: "stops when keep does", stopAfter: perPage + 5, wantItems: perPage + 5, wantPages: []string{"1", "2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pages []string
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				query := r.URL.Query()
+				if query.Get("per_page") != strconv.Itoa(perPage) {
+					t.Errorf("per_page = %q", query.Get("per_page"))
+				}
+				if query.Get("sort") != "pushed" {
+					t.Errorf("sort = %q, want pushed", query.Get("sort"))
+				}
+				pages = append(pages, query.Get("page"))
+
+				page, _ := strconv.Atoi(query.Get("page"))
+				var items []int
+				for i := (page - 1) * perPage; i < page*perPage && i < total; i++ {
+					items = append(items, i)
+				}
+				json.NewEncoder(w).Encode(items)
+			})
+
+			var items []int
+			err := getAllPages(context.Background(), c, "user/repos", map[string][]string{"sort": {"pushed"}}, noCache, func(item int) bool {
+				if tt.stopAfter > 0 && len(items) == tt.stopAfter {
+					return false
+				}
+				items = append(items, item)
+				return true
+			})
+			if err != nil {
+				t.Fatalf("getAllPages: %v", err)
+			}
+
+			if len(items) != tt.wantItems {
+				t.Errorf("items = %d, want %d", len(items), tt.wantItems)
+			}
+			if len(pages) != len(tt.wantPages) {
+				t.Fatalf("pages = %v, want %v", pages, tt.wantPages)
+			}
+			for i := range pages {
+				if pages[i] != tt.wantPages[i] {
+					t.Errorf("pages = %v, want %v", pages, tt.wantPages)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestSearchAllStopsAtLimit(t *testing.T) {
+	var requests int
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("q") != "author:octocat" {
+			t.Errorf("q = %q", r.URL.Query().Get("q"))
+		}
+		page := searchPage[int]{TotalCount: 5000, Items: make([]int, perPage)}
+		json.NewEncoder(w).Encode(page)
+	})
+
+	err := searchAll(context.Background(), c, "search/issues", "author:octocat", func(int) bool { return true })
+	if err != nil {
+		t.Fatalf("searchAll: %v", err)
+	}
+	if requests != maxSearchPages {
+		t.Errorf("requests = %d, want %d", requests, maxSearchPages)
+	}
+}
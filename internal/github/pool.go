@@ -0,0 +1,116 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultConcurrency is the number of repositories fetched in parallel when
+// Options.Concurrency is not set.
+const DefaultConcurrency = 8
+
+// Progress is reported to Options.Progress as each repository finishes, and
+// with Wait set whenever a request is held back by a rate limit.
+type Progress struct {
+	Host  string
+	Stage string
+	Repo  string
+	Done  int
+	Total int
+	Wait  time.Duration
+}
+
+// forEachRepo runs fn for every repo (or other per-repo key, such as a
+// pull request reference) on a bounded pool of workers. A repo whose fn
+// fails is skipped so the others still count; the failures are passed to
+// Options.Warn, or returned joined without one. A rate limit that outlasts
+// the retries would fail every other repo too, so it cancels the rest and
+// is returned.
+func (c *Client) forEachRepo(ctx context.Context, stage string, repos []string, fn func(ctx context.Context, repo string) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan string)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		limitErr error
+		failed   []error
+		done     int
+	)
+
+	workers := c.concurrency
+	if workers > len(repos) {
+		workers = len(repos)
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for repo := range jobs {
+				err := fn(ctx, repo)
+
+				mu.Lock()
+				done++
+				switch {
+				case err == nil || ctx.Err() != nil:
+				case IsRateLimited(err):
+					if limitErr == nil {
+						limitErr = err
+						cancel()
+					}
+				default:
+					failed = append(failed, err)
+				}
+				current := done
+				mu.Unlock()
+
+				c.reportProgress(Progress{Stage: stage, Repo: repo, Done: current, Total: len(repos)})
+			}
+		}()
+	}
+
+feed:
+	for _, repo := range repos {
+		select {
+		case jobs <- repo:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if limitErr != nil {
+		return limitErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	err := fmt.Errorf("github: skipped %d of %d %s on %s:\n%v", len(failed), len(repos), stage, c.host, errors.Join(failed...))
+	if c.warn == nil {
+		return err
+	}
+	c.warn(err)
+	return nil
+}
+
+func (c *Client) reportProgress(p Progress) {
+	if c.progress == nil {
+		return
+	}
+
+	p.Host = c.host
+
+	c.progressMu.Lock()
+	defer c.progressMu.Unlock()
+	c.progress(p)
+}
@@ -0,0 +1,94 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestForEachRepo(t *testing.T) {
+	repos := []string{"acme/a", "acme/b", "acme/c", "acme/d"}
+	limited := &RateLimitError{Method: "GET", URL: "repos/acme/b"}
+
+	tests := []struct {
+		name        string
+		failing     map[string]error
+		warn        bool
+		wantDone    int // repos fn finished without error, at least
+		wantErr     string
+		wantWarning string
+	}{
+		{name: "all succeed", wantDone: 4},
+		{
+			name:        "failures are reported",
+			failing:     map[string]error{"acme/b": errors.New("502 Bad Gateway"), "acme/d": errors.New("timeout")},
+			warn:        true,
+			wantDone:    2,
+			wantWarning: "skipped 2 of 4 commits",
+		},
+		{
+			name:     "failures are returned without a warn",
+			failing:  map[string]error{"acme/c": errors.New("502 Bad Gateway")},
+			wantDone: 3,
+			wantErr:  "502 Bad Gateway",
+		},
+		{
+			name:    "a rate limit stops the rest",
+			failing: map[string]error{"acme/a": limited},
+			warn:    true,
+			wantErr: "rate limited",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var warnings []string
+			c := &Client{host: "github.com", concurrency: 1}
+			if tt.warn {
+				c.warn = func(err error) { warnings = append(warnings, err.Error()) }
+			}
+
+			var (
+				mu   sync.Mutex
+				done []string
+			)
+			err := c.forEachRepo(context.Background(), "commits", repos, func(ctx context.Context, repo string) error {
+				if err := tt.failing[repo]; err != nil {
+					if IsRateLimited(err) {
+						return err
+					}
+					return fmt.Errorf("failed to get commits for %s: %v", repo, err)
+				}
+				mu.Lock()
+				done = append(done, repo)
+				mu.Unlock()
+				return nil
+			})
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("err = %v, want it to mention %q", err, tt.wantErr)
+			}
+			if len(done) < tt.wantDone {
+				t.Errorf("done = %v, want at least %d", done, tt.wantDone)
+			}
+			if tt.wantWarning != "" {
+				if len(warnings) != 1 || !strings.Contains(warnings[0], tt.wantWarning) {
+					t.Errorf("warnings = %q, want one mentioning %q", warnings, tt.wantWarning)
+				}
+				for repo := range tt.failing {
+					if !strings.Contains(warnings[0], repo) {
+						t.Errorf("warning %q doesn't name %s", warnings[0], repo)
+					}
+				}
+			} else if len(warnings) > 0 {
+				t.Errorf("unexpected warnings %q", warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxRetries = 3
+
+	// GitHub asks clients that hit a secondary limit without a Retry-After
+	// header to wait at least a minute before trying again.
+	secondaryLimitBackoff = time.Minute
+)
+
+// rateLimiter tracks the primary rate limit reported by the API so that
+// concurrent workers pause together once the budget is spent.
+type rateLimiter struct {
+	mu        sync.Mutex
+	remaining int
+	reset     time.Time
+	known     bool
+}
+
+func (r *rateLimiter) update(resp *http.Response) {
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.remaining = remaining
+	r.reset = time.Unix(reset, 0)
+	r.known = true
+}
+
+// delay returns how long to hold off before the next request: zero unless
+// the last response said the primary budget was spent.
+func (r *rateLimiter) delay() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.known || r.remaining > 0 {
+		return 0
+	}
+	return time.Until(r.reset)
+}
+
+// retryDelay decides whether a response is a rate-limit rejection and, if
+// so, how long to wait before retrying.
+func retryDelay(resp *http.Response, data []byte, attempt int) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return time.Until(time.Unix(reset, 0)), true
+		}
+	}
+
+	// A plain 403 is a permissions problem, not a limit; secondary limits
+	// only say so in the message
+	if resp.StatusCode == http.StatusForbidden && !isSecondaryLimit(data) {
+		return 0, false
+	}
+
+	return secondaryLimitBackoff << attempt, true
+}
+
+// isSecondaryLimit reports whether a 403 body is GitHub's secondary rate
+// limit, which older servers call abuse detection.
+func isSecondaryLimit(data []byte) bool {
+	var body struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(data, &body) != nil {
+		return false
+	}
+	message := strings.ToLower(body.Message)
+	return strings.Contains(message, "secondary rate limit") || strings.Contains(message, "abuse detection")
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,220 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/digitaldrywood/timetracker/internal/activity"
+)
+
+// Reflog entries further apart than reflogSessionGap start a new session,
+// matching the gap the tracker's estimates use.
+const reflogSessionGap = 45 * time.Minute
+
+// Session is a stretch of continuous work in a local clone, reconstructed
+// from the checkouts, commits, rebases and amends in its HEAD reflog.
+type Session struct {
+	Repository string
+	Path       string
+	Start      time.Time
+	End        time.Time
+	Events     int
+	Branches   []string // in the order they were worked on
+	Switches   []BranchSwitch
+}
+
+// BranchSwitch is a checkout from one branch to another during a session.
+type BranchSwitch struct {
+	At   time.Time
+	From string
+	To   string
+}
+
+type reflogEntry struct {
+	at      time.Time
+	subject string
+}
+
+// ReflogSource reports the sessions found in local clones' reflogs.
+type ReflogSource struct {
+	local *LocalCollector
+}
+
+// NewReflogSource looks for reflogs in the clones local discovers.
+func NewReflogSource(local *LocalCollector) *ReflogSource {
+	return &ReflogSource{local: local}
+}
+
+func (r *ReflogSource) Name() string {
+	return "reflog"
+}
+
+func (r *ReflogSource) Fetch(ctx context.Context, from, to time.Time) ([]activity.Item, error) {
+	sessions, err := r.local.GetSessions(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []activity.Item
+	for _, session := range sessions {
+		var switches []string
+		for _, s := range session.Switches {
+			switches = append(switches, fmt.Sprintf("%s %s -> %s", s.At.Local().Format("15:04"), s.From, s.To))
+		}
+
+		// The branch checked out when the session ended
+		branch := ""
+		if len(session.Switches) > 0 {
+			branch = session.Switches[len(session.Switches)-1].To
+		} else if len(session.Branches) > 0 {
+			branch = session.Branches[0]
+		}
+
+		items = append(items, activity.Item{
+			Source:     r.Name(),
+			Kind:       activity.KindSession,
+			Repository: session.Repository,
+			Time:       session.Start,
+			End:        session.End,
+			Title:      "Worked on " + strings.Join(session.Branches, ", "),
+			Body:       strings.Join(switches, "\n"),
+			URL:        "file://" + session.Path,
+			Branch:     branch,
+			Path:       session.Path,
+			Number:     len(session.Switches),
+			Local:      true,
+		})
+	}
+	return items, nil
+}
+
+// GetSessions groups each clone's HEAD reflog entries between from and to
+// into sessions, tracking which branches were checked out.
+func (l *LocalCollector) GetSessions(ctx context.Context, from, to time.Time) ([]Session, error) {
+	var (
+		mu  sync.Mutex
+		all []Session
+	)
+
+	// Each worktree keeps its own HEAD reflog
+	repos, err := l.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = l.forEachRepo(ctx, repos, func(repo localRepo) {
+		sessions := l.getRepoSessions(ctx, repo, from, to)
+		if len(sessions) == 0 {
+			return
+		}
+
+		mu.Lock()
+		all = append(all, sessions...)
+		mu.Unlock()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Start.Before(all[j].Start) })
+	return all, nil
+}
+
+func (l *LocalCollector) getRepoSessions(ctx context.Context, repo localRepo, from, to time.Time) []Session {
+	repoPath := repo.path
+	output := git(ctx, repoPath, "reflog", "show", "--date=unix", "--format=%gd%x1f%gs", "HEAD")
+	if output == "" {
+		return nil
+	}
+
+	var entries []reflogEntry
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		selector, subject, ok := strings.Cut(line, "\x1f")
+		if !ok {
+			continue
+		}
+		at, ok := parseReflogDate(selector)
+		if !ok {
+			continue
+		}
+		entries = append(entries, reflogEntry{at: at, subject: subject})
+	}
+
+	// The reflog is newest first; entries within a second keep their order
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	// The branch checked out before the window is the one the first later
+	// checkout moved away from, or the current branch if there was none
+	branch := ""
+	for _, entry := range entries {
+		if fromBranch, _, ok := parseCheckout(entry.subject); ok && !entry.at.Before(from) {
+			branch = fromBranch
+			break
+		}
+	}
+	if branch == "" {
+		branch = strings.TrimSpace(git(ctx, repoPath, "rev-parse", "--abbrev-ref", "HEAD"))
+	}
+
+	var (
+		sessions []Session
+		current  *Session
+	)
+	for _, entry := range entries {
+		if entry.at.Before(from) || !entry.at.Before(to) {
+			continue
+		}
+
+		if current == nil || entry.at.Sub(current.End) > reflogSessionGap {
+			sessions = append(sessions, Session{Path: repoPath, Start: entry.at, Branches: []string{branch}})
+			current = &sessions[len(sessions)-1]
+		}
+		current.End = entry.at
+		current.Events++
+
+		if fromBranch, toBranch, ok := parseCheckout(entry.subject); ok && fromBranch != toBranch {
+			current.Switches = append(current.Switches, BranchSwitch{At: entry.at, From: fromBranch, To: toBranch})
+			if !slices.Contains(current.Branches, toBranch) {
+				current.Branches = append(current.Branches, toBranch)
+			}
+			branch = toBranch
+		}
+	}
+
+	for i := range sessions {
+		sessions[i].Repository = repo.name
+	}
+	return sessions
+}
+
+// parseReflogDate reads the time from a selector like HEAD@{1700000000},
+// as printed with --date=unix.
+func parseReflogDate(selector string) (time.Time, bool) {
+	_, rest, ok := strings.Cut(selector, "@{")
+	if !ok {
+		return time.Time{}, false
+	}
+	seconds, err := strconv.ParseInt(strings.TrimSuffix(rest, "}"), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(seconds, 0), true
+}
+
+// parseCheckout reads "checkout: moving from main to feature/x".
+func parseCheckout(subject string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(subject, "checkout: moving from ")
+	if !ok {
+		return "", "", false
+	}
+	fromBranch, toBranch, ok := strings.Cut(rest, " to ")
+	return fromBranch, toBranch, ok
+}
@@ -0,0 +1,50 @@
+package github
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseReflogDate(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     time.Time
+		wantOK   bool
+	}{
+		{"HEAD@{1700000000}", time.Unix(1700000000, 0), true},
+		{"refs/heads/main@{1768298400}", time.Unix(1768298400, 0), true},
+		{"HEAD@{0}", time.Unix(0, 0), true},
+		{"HEAD@{2}", time.Unix(2, 0), true}, // an index, if --date was missing
+		{"HEAD@{2026-01-13 10:00:00}", time.Time{}, false},
+		{"HEAD@{}", time.Time{}, false},
+		{"HEAD", time.Time{}, false},
+		{"", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseReflogDate(tt.selector)
+		if ok != tt.wantOK || !got.Equal(tt.want) {
+			t.Errorf("parseReflogDate(%q) = %v, %t, want %v, %t", tt.selector, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestParseCheckout(t *testing.T) {
+	tests := []struct {
+		subject  string
+		wantFrom string
+		wantTo   string
+		wantOK   bool
+	}{
+		{"checkout: moving from main to feature/x", "main", "feature/x", true},
+		{"checkout: moving from 1a2b3c4 to main", "1a2b3c4", "main", true},
+		{"commit: Add search", "", "", false},
+		{"checkout: moving from main", "", "", false},
+		{"reset: moving to HEAD~1", "", "", false},
+	}
+	for _, tt := range tests {
+		from, to, ok := parseCheckout(tt.subject)
+		if ok != tt.wantOK || ok && (from != tt.wantFrom || to != tt.wantTo) {
+			t.Errorf("parseCheckout(%q) = %q, %q, %t, want %q, %q, %t", tt.subject, from, to, ok, tt.wantFrom, tt.wantTo, tt.wantOK)
+		}
+	}
+}
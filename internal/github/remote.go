@@ -0,0 +1,46 @@
+package github
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ParseRemoteURL turns a git remote URL into a host/owner/repo identity,
+// matching how repositories are named by the API clients. It understands
+// scp-style (git@host:owner/repo.git), ssh:// and http(s):// remotes on any
+// host, and keeps nested group paths intact.
+func ParseRemoteURL(remote string) (string, bool) {
+	remote = strings.TrimSpace(remote)
+	if remote == "" {
+		return "", false
+	}
+
+	var host, path string
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil {
+			return "", false
+		}
+		host = u.Hostname()
+		path = u.Path
+	} else {
+		// scp-style: [user@]host:path
+		hostPart, pathPart, ok := strings.Cut(remote, ":")
+		if !ok {
+			return "", false
+		}
+		if _, after, found := strings.Cut(hostPart, "@"); found {
+			hostPart = after
+		}
+		host = hostPart
+		path = pathPart
+	}
+
+	path = strings.Trim(path, "/")
+	path = strings.TrimSuffix(path, ".git")
+	if host == "" || !strings.Contains(path, "/") {
+		return "", false
+	}
+
+	return strings.ToLower(host) + "/" + path, true
+}
@@ -0,0 +1,149 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Review is a review or inline review comment the user left on a pull
+// request, usually someone else's.
+type Review struct {
+	Repository  string
+	PRNumber    int
+	PRTitle     string
+	PRURL       string
+	URL         string
+	State       string // APPROVED, CHANGES_REQUESTED, COMMENTED, or COMMENT for inline comments
+	Body        string
+	SubmittedAt time.Time
+}
+
+type searchIssue struct {
+	Number        int       `json:"number"`
+	Title         string    `json:"title"`
+	HTMLURL       string    `json:"html_url"`
+	RepositoryURL string    `json:"repository_url"`
+	State         string    `json:"state"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	User          struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+// repository returns owner/name from the API URL search results carry.
+func (i searchIssue) repository() string {
+	_, name, _ := strings.Cut(i.RepositoryURL, "/repos/")
+	return name
+}
+
+// GetReviews returns the reviews and review comments the user submitted
+// between since and until on pull requests they didn't author.
+func (c *Client) GetReviews(ctx context.Context, since, until time.Time) ([]Review, error) {
+	query := fmt.Sprintf("type:pr reviewed-by:%s -author:%s updated:>=%s",
+		c.username, c.username, since.Format("2006-01-02"))
+
+	prs := make(map[string]searchIssue)
+	var refs []string
+	err := searchAll(ctx, c, "search/issues", query, func(issue searchIssue) bool {
+		repo := issue.repository()
+		if !c.allowRepository(repo) {
+			return true
+		}
+		ref := fmt.Sprintf("%s#%d", repo, issue.Number)
+		prs[ref] = issue
+		refs = append(refs, ref)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search reviewed pull requests: %v", err)
+	}
+
+	var (
+		mu         sync.Mutex
+		allReviews []Review
+	)
+
+	err = c.forEachRepo(ctx, "reviews", refs, func(ctx context.Context, ref string) error {
+		reviews, err := c.getPullRequestReviews(ctx, prs[ref], since, until)
+		if err != nil {
+			if IsNotFound(err) || IsForbidden(err) {
+				return nil
+			}
+			if IsRateLimited(err) {
+				return err
+			}
+			return fmt.Errorf("failed to get reviews for %s: %v", ref, err)
+		}
+
+		mu.Lock()
+		allReviews = append(allReviews, reviews...)
+		mu.Unlock()
+		return nil
+	})
+	return allReviews, err
+}
+
+func (c *Client) getPullRequestReviews(ctx context.Context, pr searchIssue, since, until time.Time) ([]Review, error) {
+	repo := pr.repository()
+	base := fmt.Sprintf("repos/%s/pulls/%d", repo, pr.Number)
+	inRange := func(t time.Time) bool {
+		return !t.Before(since) && t.Before(until)
+	}
+
+	newReview := func(state, body, htmlURL string, at time.Time) Review {
+		return Review{
+			Repository:  c.repoID(repo),
+			PRNumber:    pr.Number,
+			PRTitle:     pr.Title,
+			PRURL:       pr.HTMLURL,
+			URL:         htmlURL,
+			State:       state,
+			Body:        body,
+			SubmittedAt: at,
+		}
+	}
+
+	var reviews []Review
+	err := getAllPages(ctx, c, base+"/reviews", nil, noCache, func(review struct {
+		State       string    `json:"state"`
+		Body        string    `json:"body"`
+		HTMLURL     string    `json:"html_url"`
+		SubmittedAt time.Time `json:"submitted_at"`
+		User        struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	}) bool {
+		if review.User.Login == c.username && inRange(review.SubmittedAt) {
+			reviews = append(reviews, newReview(review.State, review.Body, review.HTMLURL, review.SubmittedAt))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{"since": {since.UTC().Format(time.RFC3339)}}
+	err = getAllPages(ctx, c, base+"/comments", params, noCache, func(comment struct {
+		Body      string    `json:"body"`
+		HTMLURL   string    `json:"html_url"`
+		CreatedAt time.Time `json:"created_at"`
+		User      struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	}) bool {
+		if comment.User.Login == c.username && inRange(comment.CreatedAt) {
+			reviews = append(reviews, newReview("COMMENT", comment.Body, comment.HTMLURL, comment.CreatedAt))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
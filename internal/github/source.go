@@ -0,0 +1,137 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/digitaldrywood/timetracker/internal/activity"
+)
+
+// Source reports a GitHub host's commits, pull requests, reviews and issue
+// activity as activity items.
+type Source struct {
+	client *Client
+}
+
+func NewSource(c *Client) *Source {
+	return &Source{client: c}
+}
+
+func (s *Source) Name() string {
+	return "github:" + s.client.host
+}
+
+// Username returns the login activity is collected for.
+func (s *Source) Username() string {
+	return s.client.username
+}
+
+func (s *Source) Fetch(ctx context.Context, from, to time.Time) ([]activity.Item, error) {
+	name := s.Name()
+
+	commits, err := s.client.GetCommits(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commits: %v", err)
+	}
+
+	prs, err := s.client.GetPullRequests(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull requests: %v", err)
+	}
+
+	reviews, err := s.client.GetReviews(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviews: %v", err)
+	}
+
+	issues, err := s.client.GetIssueActivity(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue activity: %v", err)
+	}
+
+	var items []activity.Item
+	for _, commit := range commits {
+		items = append(items, CommitItem(name, commit))
+	}
+	for _, pr := range prs {
+		items = append(items, PullRequestItem(name, pr))
+	}
+	for _, review := range reviews {
+		items = append(items, ReviewItem(name, review))
+	}
+	for _, issue := range issues {
+		items = append(items, IssueItem(name, issue))
+	}
+
+	return items, nil
+}
+
+// CommitItem converts a commit into an activity item. The other providers
+// share these conversions since they report the same shapes.
+func CommitItem(source string, commit Commit) activity.Item {
+	return activity.Item{
+		Source:     source,
+		Kind:       activity.KindCommit,
+		Repository: commit.Repository,
+		Time:       commit.AuthorDate,
+		Title:      strings.Split(commit.Message, "\n")[0],
+		Body:       commit.Message,
+		URL:        commit.URL,
+		SHA:        commit.SHA,
+		Branch:     commit.Branch,
+		Path:       commit.Path,
+		Number:     commit.PullRequest,
+		Pushed:     !commit.Unpushed,
+
+		Additions:    commit.Additions,
+		Deletions:    commit.Deletions,
+		FilesChanged: commit.FilesChanged,
+	}
+}
+
+// PullRequestItem converts a pull request, timed at its last update.
+func PullRequestItem(source string, pr PullRequest) activity.Item {
+	return activity.Item{
+		Source:     source,
+		Kind:       activity.KindPullRequest,
+		Repository: pr.Repository,
+		Time:       pr.UpdatedAt,
+		Title:      pr.Title,
+		URL:        pr.URL,
+		Number:     pr.Number,
+		State:      pr.State,
+	}
+}
+
+// ReviewItem converts a review. The item links to the reviewed pull request
+// and carries its title, since that is what time entries refer to.
+func ReviewItem(source string, review Review) activity.Item {
+	return activity.Item{
+		Source:     source,
+		Kind:       activity.KindReview,
+		Repository: review.Repository,
+		Time:       review.SubmittedAt,
+		Title:      review.PRTitle,
+		Body:       review.Body,
+		URL:        review.PRURL,
+		Number:     review.PRNumber,
+		State:      review.State,
+	}
+}
+
+// IssueItem converts issue activity; State holds the action taken.
+func IssueItem(source string, issue IssueActivity) activity.Item {
+	return activity.Item{
+		Source:     source,
+		Kind:       activity.KindIssue,
+		Repository: issue.Repository,
+		Time:       issue.At,
+		Title:      issue.Title,
+		Body:       issue.Detail,
+		URL:        issue.URL,
+		Number:     issue.Number,
+		State:      issue.Action,
+	}
+}
@@ -0,0 +1,165 @@
+package github
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WorkInProgress is uncommitted work in a local clone: either files
+// changed in the working tree or a stash, with when the work happened.
+type WorkInProgress struct {
+	Repository string
+	Path       string // clone directory
+	Branch     string
+	Stash      string // stash message; empty for working tree changes
+	Files      []string
+	Additions  int
+	Deletions  int
+	Start      time.Time // earliest file modification, or when stashed
+	End        time.Time
+}
+
+// GetWorkInProgress reports clones with uncommitted changes to files
+// modified between from and to, and stashes created in that window.
+// Modification times only survive until a file is touched again, so this
+// is most accurate for recent days.
+func (l *LocalCollector) GetWorkInProgress(ctx context.Context, from, to time.Time) ([]WorkInProgress, error) {
+	var (
+		mu  sync.Mutex
+		all []WorkInProgress
+	)
+
+	// Every worktree has its own uncommitted changes, but stashes are
+	// shared by all of a clone's worktrees
+	repos, err := l.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	clones, err := l.clones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stashesFrom := make(map[string]bool)
+	for _, clone := range clones {
+		stashesFrom[clone.path] = true
+	}
+
+	err = l.forEachRepo(ctx, repos, func(repo localRepo) {
+		wip := l.getRepoWorkInProgress(ctx, repo, stashesFrom[repo.path], from, to)
+		if len(wip) == 0 {
+			return
+		}
+
+		mu.Lock()
+		all = append(all, wip...)
+		mu.Unlock()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Start.Before(all[j].Start) })
+	return all, nil
+}
+
+func (l *LocalCollector) getRepoWorkInProgress(ctx context.Context, repo localRepo, stashes bool, from, to time.Time) []WorkInProgress {
+	repoPath := repo.path
+	var wip []WorkInProgress
+	inRange := func(t time.Time) bool {
+		return !t.Before(from) && t.Before(to)
+	}
+
+	branch := strings.TrimSpace(git(ctx, repoPath, "rev-parse", "--abbrev-ref", "HEAD"))
+
+	changes := WorkInProgress{Repository: repo.name, Path: repoPath, Branch: branch}
+	for _, path := range changedPaths(git(ctx, repoPath, "status", "--porcelain", "-z")) {
+		info, err := os.Stat(filepath.Join(repoPath, path))
+		if err != nil || !inRange(info.ModTime()) {
+			continue
+		}
+
+		changes.Files = append(changes.Files, path)
+		if changes.Start.IsZero() || info.ModTime().Before(changes.Start) {
+			changes.Start = info.ModTime()
+		}
+		if info.ModTime().After(changes.End) {
+			changes.End = info.ModTime()
+		}
+	}
+	if len(changes.Files) > 0 {
+		// Only the files touched in the window, so a long-lived dirty
+		// checkout doesn't count the same changes every day
+		args := append([]string{"--literal-pathspecs", "diff", "--numstat", "HEAD", "--"}, changes.Files...)
+		changes.Additions, changes.Deletions, _ = parseNumstat(git(ctx, repoPath, args...))
+		wip = append(wip, changes)
+	}
+
+	if !stashes {
+		return wip
+	}
+
+	// A stash commit's committer date is when it was stashed
+	stashList := git(ctx, repoPath, "stash", "list", "--format=%cI%x1f%gs")
+	for _, line := range strings.Split(stashList, "\n") {
+		at, message, ok := strings.Cut(line, "\x1f")
+		if !ok {
+			continue
+		}
+		stashedAt, err := time.Parse(time.RFC3339, at)
+		if err != nil || !inRange(stashedAt) {
+			continue
+		}
+		wip = append(wip, WorkInProgress{
+			Repository: repo.name,
+			Path:       repoPath,
+			Branch:     branch,
+			Stash:      message,
+			Start:      stashedAt,
+			End:        stashedAt,
+		})
+	}
+
+	return wip
+}
+
+// changedPaths lists the tracked paths in `git status --porcelain -z`
+// output; untracked and ignored files are left out. Renames list the new
+// path only.
+func changedPaths(status string) []string {
+	var paths []string
+	entries := strings.Split(status, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+
+		// Renames and copies are followed by the original path
+		if entry[0] == 'R' || entry[0] == 'C' {
+			i++
+		}
+
+		if entry[:2] == "??" || entry[:2] == "!!" {
+			continue
+		}
+		paths = append(paths, entry[3:])
+	}
+	return paths
+}
+
+// git runs a git command in dir and returns its output, or "" on error.
+func git(ctx context.Context, dir string, args ...string) string {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return string(output)
+}
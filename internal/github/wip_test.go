@@ -0,0 +1,30 @@
+package github
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestChangedPaths(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		want   []string
+	}{
+		{"empty", "", nil},
+		{"modified in the worktree", " M main.go\x00", []string{"main.go"}},
+		{"staged and modified", "MM main.go\x00A  new.go\x00 D old.go\x00", []string{"main.go", "new.go", "old.go"}},
+		{"untracked and ignored", "?? scratch.txt\x00!! build/\x00 M main.go\x00", []string{"main.go"}},
+		{"rename lists the new path", "R  new.go\x00old.go\x00 M main.go\x00", []string{"new.go", "main.go"}},
+		{"copy lists the new path", "C  copy.go\x00main.go\x00", []string{"copy.go"}},
+		{"spaces in paths", " M docs/read me.md\x00", []string{"docs/read me.md"}},
+		{"no trailing NUL", " M main.go", []string{"main.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := changedPaths(tt.status); !slices.Equal(got, tt.want) {
+				t.Errorf("changedPaths(%q) = %q, want %q", tt.status, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/digitaldrywood/timetracker/internal/github"
+)
+
+type event struct {
+	ProjectID   int       `json:"project_id"`
+	ActionName  string    `json:"action_name"`
+	TargetType  string    `json:"target_type"`
+	TargetIID   int       `json:"target_iid"`
+	TargetTitle string    `json:"target_title"`
+	CreatedAt   time.Time `json:"created_at"`
+	Note        *struct {
+		ID           int    `json:"id"`
+		Body         string `json:"body"`
+		NoteableType string `json:"noteable_type"`
+		NoteableIID  int    `json:"noteable_iid"`
+	} `json:"note"`
+}
+
+type mergeRequest struct {
+	IID       int       `json:"iid"`
+	ProjectID int       `json:"project_id"`
+	Title     string    `json:"title"`
+	WebURL    string    `json:"web_url"`
+	State     string    `json:"state"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Author    struct {
+		Username string `json:"username"`
+	} `json:"author"`
+}
+
+// getEvents returns the user's events between since and until, fetched
+// once per range since commits and reviews both read them. GitLab's
+// after/before filters are whole, exclusive dates, so the window is widened
+// by a day each side and trimmed here.
+func (c *Client) getEvents(ctx context.Context, since, until time.Time) ([]event, error) {
+	key := since.UTC().Format(time.RFC3339) + "/" + until.UTC().Format(time.RFC3339)
+
+	c.eventsMu.Lock()
+	defer c.eventsMu.Unlock()
+
+	if events, ok := c.events[key]; ok {
+		return events, nil
+	}
+
+	params := url.Values{
+		"after":  {since.AddDate(0, 0, -1).Format("2006-01-02")},
+		"before": {until.AddDate(0, 0, 1).Format("2006-01-02")},
+		"sort":   {"asc"},
+	}
+
+	var events []event
+	err := getAllPages(ctx, c, fmt.Sprintf("users/%d/events", c.userID), params, func(e event) {
+		if !e.CreatedAt.Before(since) && e.CreatedAt.Before(until) {
+			events = append(events, e)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.events[key] = events
+	return events, nil
+}
+
+// GetCommits returns the user's commits on any branch of the projects they
+// pushed to between since and until.
+func (c *Client) GetCommits(ctx context.Context, since, until time.Time) ([]github.Commit, error) {
+	events, err := c.getEvents(ctx, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events: %v", err)
+	}
+
+	var projectIDs []int
+	seen := make(map[int]bool)
+	for _, e := range events {
+		if strings.HasPrefix(e.ActionName, "pushed") && !seen[e.ProjectID] {
+			seen[e.ProjectID] = true
+			projectIDs = append(projectIDs, e.ProjectID)
+		}
+	}
+
+	var commits []github.Commit
+	for _, id := range projectIDs {
+		p, err := c.getProject(ctx, id)
+		if err != nil {
+			if IsNotFound(err) || IsForbidden(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		params := url.Values{
+			"since":      {since.UTC().Format(time.RFC3339)},
+			"until":      {until.UTC().Format(time.RFC3339)},
+			"all":        {"true"},
+			"with_stats": {"true"},
+		}
+		err = getAllPages(ctx, c, fmt.Sprintf("projects/%d/repository/commits", id), params, func(commit struct {
+			ID          string    `json:"id"`
+			Message     string    `json:"message"`
+			WebURL      string    `json:"web_url"`
+			AuthorName  string    `json:"author_name"`
+			AuthorEmail string    `json:"author_email"`
+			AuthoredAt  time.Time `json:"authored_date"`
+			Stats       struct {
+				Additions int `json:"additions"`
+				Deletions int `json:"deletions"`
+			} `json:"stats"`
+		}) {
+			if !c.identity.MatchesCommit(commit.AuthorName, commit.AuthorEmail, "", commit.Message) {
+				return
+			}
+			commits = append(commits, github.Commit{
+				SHA:        commit.ID,
+				Message:    commit.Message,
+				URL:        commit.WebURL,
+				Repository: c.repoID(p),
+				AuthorDate: commit.AuthoredAt,
+				Additions:  commit.Stats.Additions,
+				Deletions:  commit.Stats.Deletions,
+			})
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get commits for %s: %v", p.PathWithNamespace, err)
+		}
+	}
+
+	return commits, nil
+}
+
+// GetMergeRequests returns merge requests the user opened that were
+// created or updated between since and until, as PullRequests.
+func (c *Client) GetMergeRequests(ctx context.Context, since, until time.Time) ([]github.PullRequest, error) {
+	params := url.Values{
+		"scope":          {"created_by_me"},
+		"state":          {"all"},
+		"updated_after":  {since.UTC().Format(time.RFC3339)},
+		"updated_before": {until.UTC().Format(time.RFC3339)},
+	}
+
+	var mrs []mergeRequest
+	err := getAllPages(ctx, c, "merge_requests", params, func(mr mergeRequest) {
+		mrs = append(mrs, mr)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list merge requests: %v", err)
+	}
+
+	var prs []github.PullRequest
+	for _, mr := range mrs {
+		p, err := c.getProject(ctx, mr.ProjectID)
+		if err != nil {
+			// Projects we can no longer see are not worth failing the run for
+			if IsNotFound(err) || IsForbidden(err) {
+				continue
+			}
+			return nil, err
+		}
+		prs = append(prs, github.PullRequest{
+			Number:     mr.IID,
+			Title:      mr.Title,
+			URL:        mr.WebURL,
+			Repository: c.repoID(p),
+			State:      mergeRequestState(mr.State),
+			CreatedAt:  mr.CreatedAt,
+			UpdatedAt:  mr.UpdatedAt,
+		})
+	}
+
+	return prs, nil
+}
+
+// GetReviews returns approvals and comments the user left on other
+// people's merge requests between since and until.
+func (c *Client) GetReviews(ctx context.Context, since, until time.Time) ([]github.Review, error) {
+	events, err := c.getEvents(ctx, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events: %v", err)
+	}
+
+	var reviews []github.Review
+	for _, e := range events {
+		var state, body, anchor string
+		iid := e.TargetIID
+
+		switch {
+		case e.ActionName == "approved" && e.TargetType == "MergeRequest":
+			state = "APPROVED"
+		case e.Note != nil && e.Note.NoteableType == "MergeRequest":
+			state = "COMMENT"
+			body = e.Note.Body
+			iid = e.Note.NoteableIID
+			anchor = fmt.Sprintf("#note_%d", e.Note.ID)
+		default:
+			continue
+		}
+
+		mr, err := c.getMergeRequest(ctx, e.ProjectID, iid)
+		if err != nil {
+			if IsNotFound(err) || IsForbidden(err) {
+				continue
+			}
+			return nil, err
+		}
+		if mr.Author.Username == c.username {
+			continue
+		}
+
+		p, err := c.getProject(ctx, e.ProjectID)
+		if err != nil {
+			if IsNotFound(err) || IsForbidden(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		reviews = append(reviews, github.Review{
+			Repository:  c.repoID(p),
+			PRNumber:    mr.IID,
+			PRTitle:     mr.Title,
+			PRURL:       mr.WebURL,
+			URL:         mr.WebURL + anchor,
+			State:       state,
+			Body:        body,
+			SubmittedAt: e.CreatedAt,
+		})
+	}
+
+	sort.Slice(reviews, func(i, j int) bool { return reviews[i].SubmittedAt.Before(reviews[j].SubmittedAt) })
+	return reviews, nil
+}
+
+func (c *Client) getMergeRequest(ctx context.Context, projectID, iid int) (mergeRequest, error) {
+	var mr mergeRequest
+	_, err := c.get(ctx, fmt.Sprintf("projects/%d/merge_requests/%d", projectID, iid), nil, &mr)
+	return mr, err
+}
+
+// mergeRequestState maps GitLab states onto the GitHub ones tracker shows.
+func mergeRequestState(state string) string {
+	switch state {
+	case "opened":
+		return "OPEN"
+	case "merged":
+		return "MERGED"
+	default:
+		return strings.ToUpper(state)
+	}
+}
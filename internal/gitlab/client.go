@@ -0,0 +1,278 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/digitaldrywood/timetracker/internal/github"
+)
+
+const DefaultBaseURL = "https://gitlab.com"
+
+type Client struct {
+	host       string
+	apiURL     string
+	token      string
+	httpClient *http.Client
+	identity   github.Identity
+
+	userID   int
+	username string
+
+	mu       sync.Mutex
+	projects map[int]project
+
+	eventsMu sync.Mutex
+	events   map[string][]event
+}
+
+// Options configures a Client. BaseURL is the instance root, e.g.
+// https://gitlab.com or https://gitlab.acme.com for self-hosted GitLab.
+type Options struct {
+	BaseURL    string
+	Token      string
+	TokenEnv   string
+	HTTPClient *http.Client
+
+	// Identity lists extra author names and emails that count as the user
+	// when matching commits. The GitLab username is always included.
+	Identity github.Identity
+}
+
+// APIError is returned when GitLab answers with a non-2xx status code.
+type APIError struct {
+	StatusCode int
+	Method     string
+	URL        string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("gitlab: %s %s: %d %s", e.Method, e.URL, e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is a 404 from the API.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsForbidden reports whether err is a 403 from the API.
+func IsForbidden(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusForbidden
+}
+
+type project struct {
+	ID                int    `json:"id"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	WebURL            string `json:"web_url"`
+}
+
+func New(opts Options) (*Client, error) {
+	baseURL := strings.TrimSuffix(opts.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid GitLab base URL %q", opts.BaseURL)
+	}
+
+	c := &Client{
+		host:       strings.ToLower(u.Hostname()),
+		apiURL:     baseURL + "/api/v4",
+		token:      opts.Token,
+		httpClient: opts.HTTPClient,
+		identity:   opts.Identity,
+		projects:   make(map[int]project),
+		events:     make(map[string][]event),
+	}
+
+	if c.httpClient == nil {
+		c.httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	if c.token == "" {
+		for _, key := range []string{opts.TokenEnv, "GITLAB_TOKEN"} {
+			if key != "" && os.Getenv(key) != "" {
+				c.token = os.Getenv(key)
+				break
+			}
+		}
+	}
+	if c.token == "" {
+		return nil, fmt.Errorf("no GitLab token for %s: set GITLAB_TOKEN or token_env", c.host)
+	}
+
+	var user struct {
+		ID       int    `json:"id"`
+		Username string `json:"username"`
+	}
+	if _, err := c.get(context.Background(), "user", nil, &user); err != nil {
+		return nil, fmt.Errorf("failed to get current user on %s: %v", c.host, err)
+	}
+	c.userID = user.ID
+	c.username = user.Username
+	c.identity.Logins = append(c.identity.Logins, user.Username)
+
+	return c, nil
+}
+
+// Host returns the GitLab hostname this client talks to.
+func (c *Client) Host() string {
+	return c.host
+}
+
+func (c *Client) get(ctx context.Context, path string, params url.Values, out interface{}) (*http.Response, error) {
+	rawURL := c.apiURL + "/" + strings.TrimPrefix(path, "/")
+	if len(params) > 0 {
+		rawURL += "?" + params.Encode()
+	}
+
+	resp, data, err := c.send(ctx, rawURL)
+	if err != nil {
+		return resp, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &APIError{
+			StatusCode: resp.StatusCode,
+			Method:     http.MethodGet,
+			URL:        rawURL,
+			Message:    http.StatusText(resp.StatusCode),
+		}
+		var body struct {
+			Message interface{} `json:"message"`
+		}
+		if json.Unmarshal(data, &body) == nil && body.Message != nil {
+			apiErr.Message = fmt.Sprint(body.Message)
+		}
+		return resp, apiErr
+	}
+
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return resp, fmt.Errorf("gitlab: failed to parse response from %s: %v", rawURL, err)
+		}
+	}
+
+	return resp, nil
+}
+
+// send GETs rawURL, retrying when GitLab answers 429 Too Many Requests.
+func (c *Client) send(ctx context.Context, rawURL string) (*http.Response, []byte, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, nil, fmt.Errorf("gitlab: GET %s: %v", rawURL, err)
+		}
+
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return resp, nil, fmt.Errorf("gitlab: reading response from %s: %v", rawURL, err)
+		}
+
+		if resp.StatusCode != http.StatusTooManyRequests || attempt >= maxRetries {
+			return resp, data, nil
+		}
+
+		timer := time.NewTimer(retryDelay(resp, attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+const (
+	maxRetries = 3
+
+	// How long to wait on a 429 that doesn't say, doubling each retry
+	rateLimitBackoff = time.Minute
+)
+
+// retryDelay reads how long a 429 asks to wait, from Retry-After or the
+// RateLimit-Reset time.
+func retryDelay(resp *http.Response, attempt int) time.Duration {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if reset, err := strconv.ParseInt(resp.Header.Get("RateLimit-Reset"), 10, 64); err == nil {
+		if d := time.Until(time.Unix(reset, 0)); d > 0 {
+			return d
+		}
+	}
+	return rateLimitBackoff << attempt
+}
+
+// getAllPages follows X-Next-Page until GitLab reports no further pages,
+// appending each page's items via add.
+func getAllPages[T any](ctx context.Context, c *Client, path string, params url.Values, add func(T)) error {
+	query := url.Values{}
+	for key, values := range params {
+		query[key] = values
+	}
+	query.Set("per_page", "100")
+
+	for page := 1; page > 0; {
+		query.Set("page", strconv.Itoa(page))
+
+		var items []T
+		resp, err := c.get(ctx, path, query, &items)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			add(item)
+		}
+
+		page, _ = strconv.Atoi(resp.Header.Get("X-Next-Page"))
+	}
+
+	return nil
+}
+
+// getProject looks up a project once per run; events only carry its ID.
+func (c *Client) getProject(ctx context.Context, id int) (project, error) {
+	c.mu.Lock()
+	p, ok := c.projects[id]
+	c.mu.Unlock()
+	if ok {
+		return p, nil
+	}
+
+	if _, err := c.get(ctx, fmt.Sprintf("projects/%d", id), nil, &p); err != nil {
+		return project{}, err
+	}
+
+	c.mu.Lock()
+	c.projects[id] = p
+	c.mu.Unlock()
+	return p, nil
+}
+
+// repoID names a project host/group/project, matching the GitHub clients.
+func (c *Client) repoID(p project) string {
+	return c.host + "/" + p.PathWithNamespace
+}
@@ -0,0 +1,124 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/digitaldrywood/timetracker/internal/github"
+)
+
+// newTestClient returns a Client talking to handler as user 1, "octocat".
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "token" {
+			t.Errorf("PRIVATE-TOKEN = %q, want token", r.Header.Get("PRIVATE-TOKEN"))
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 1, "username": "octocat"})
+	})
+	mux.HandleFunc("/", handler)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	c, err := New(Options{
+		BaseURL:    server.URL,
+		Token:      "token",
+		HTTPClient: server.Client(),
+		Identity:   github.Identity{Emails: []string{"mona@example.com"}},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return c
+}
+
+func TestIsNotFoundAndForbidden(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantNotFound  bool
+		wantForbidden bool
+	}{
+		{"not found", &APIError{StatusCode: http.StatusNotFound}, true, false},
+		{"forbidden", &APIError{StatusCode: http.StatusForbidden}, false, true},
+		{"joined", errors.Join(errors.New("other"), &APIError{StatusCode: http.StatusNotFound}), true, false},
+		{"server error", &APIError{StatusCode: http.StatusInternalServerError}, false, false},
+		{"other error", errors.New("gitlab: 404"), false, false},
+		{"nil", nil, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsNotFound(tt.err); got != tt.wantNotFound {
+				t.Errorf("IsNotFound = %t, want %t", got, tt.wantNotFound)
+			}
+			if got := IsForbidden(tt.err); got != tt.wantForbidden {
+				t.Errorf("IsForbidden = %t, want %t", got, tt.wantForbidden)
+			}
+		})
+	}
+}
+
+func TestGetCommitsSkipsHiddenProjects(t *testing.T) {
+	at := time.Date(2026, 1, 13, 10, 0, 0, 0, time.UTC)
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v4/users/1/events":
+			var events []map[string]interface{}
+			for _, id := range []int{1, 2, 3} {
+				events = append(events, map[string]interface{}{"project_id": id, "action_name": "pushed to", "created_at": at})
+			}
+			json.NewEncoder(w).Encode(events)
+		case "/api/v4/projects/1":
+			json.NewEncoder(w).Encode(project{ID: 1, PathWithNamespace: "acme/website", WebURL: "https://gitlab.acme.com/acme/website"})
+		case "/api/v4/projects/2":
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"message": "404 Project Not Found"})
+		case "/api/v4/projects/3":
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{"message": "403 Forbidden"})
+		case "/api/v4/projects/1/repository/commits":
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"id": "abc123", "message": "Add search", "author_name": "Mona", "author_email": "mona@example.com", "authored_date": at},
+				{"id": "def456", "message": "Fix typo", "author_name": "Hubot", "author_email": "hubot@example.com", "authored_date": at},
+			})
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	commits, err := c.GetCommits(context.Background(), at.Add(-time.Hour), at.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetCommits: %v", err)
+	}
+	if len(commits) != 1 || commits[0].SHA != "abc123" {
+		t.Fatalf("commits = %+v, want only abc123", commits)
+	}
+}
+
+func TestGetReportsAPIErrors(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "404 Project Not Found"})
+	})
+
+	_, err := c.getProject(context.Background(), 2)
+	if !IsNotFound(err) {
+		t.Fatalf("IsNotFound(%v) = false", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %T, want *APIError", err)
+	}
+	if apiErr.Message != "404 Project Not Found" {
+		t.Errorf("message = %q, want the one GitLab sent", apiErr.Message)
+	}
+}
@@ -0,0 +1,60 @@
+package gitlab
+
+import (
+	"context"
+	"time"
+
+	"github.com/digitaldrywood/timetracker/internal/activity"
+	"github.com/digitaldrywood/timetracker/internal/github"
+)
+
+// Source reports a GitLab instance's commits, merge requests and reviews
+// as activity items.
+type Source struct {
+	client *Client
+}
+
+func NewSource(c *Client) *Source {
+	return &Source{client: c}
+}
+
+func (s *Source) Name() string {
+	return "gitlab:" + s.client.host
+}
+
+// Username returns the username activity is collected for.
+func (s *Source) Username() string {
+	return s.client.username
+}
+
+func (s *Source) Fetch(ctx context.Context, from, to time.Time) ([]activity.Item, error) {
+	name := s.Name()
+
+	commits, err := s.client.GetCommits(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	mrs, err := s.client.GetMergeRequests(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews, err := s.client.GetReviews(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []activity.Item
+	for _, commit := range commits {
+		items = append(items, github.CommitItem(name, commit))
+	}
+	for _, mr := range mrs {
+		items = append(items, github.PullRequestItem(name, mr))
+	}
+	for _, review := range reviews {
+		items = append(items, github.ReviewItem(name, review))
+	}
+
+	return items, nil
+}
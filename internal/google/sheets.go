@@ -2,6 +2,8 @@ package google
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"google.golang.org/api/sheets/v4"
@@ -20,6 +22,18 @@ type TimeEntry struct {
 	Description string
 	GitCommits  string
 	GitPRs      string
+
+	// Classification for the database, e.g. TaskType "bug_fix"; not
+	// written to the sheet
+	TaskType string
+	Client   string
+	Billable bool
+
+	// Repository is what a suggestion was built for, before its project
+	// was prefilled from past entries with the given Confidence (0-1);
+	// neither is written to the sheet
+	Repository string
+	Confidence float64
 }
 
 func NewSheetsClient(service *sheets.Service, spreadsheetID string) *SheetsClient {
@@ -60,7 +74,18 @@ func (s *SheetsClient) AppendTimeEntry(entry TimeEntry) error {
 }
 
 func (s *SheetsClient) GetTodayEntries() ([]TimeEntry, error) {
-	today := time.Now().Format("2006-01-02")
+	return s.GetEntriesForDate(time.Now())
+}
+
+// GetEntriesForDate returns the entries logged for date's day.
+func (s *SheetsClient) GetEntriesForDate(date time.Time) ([]TimeEntry, error) {
+	return s.GetEntries(date, date.AddDate(0, 0, 1))
+}
+
+func (s *SheetsClient) GetWeekEntries() ([]TimeEntry, error) {
+	now := time.Now()
+	weekStart := now.AddDate(0, 0, -int(now.Weekday()))
+	weekEnd := weekStart.AddDate(0, 0, 7)
 
 	resp, err := s.service.Spreadsheets.Values.Get(
 		s.spreadsheetID,
@@ -73,34 +98,44 @@ func (s *SheetsClient) GetTodayEntries() ([]TimeEntry, error) {
 
 	var entries []TimeEntry
 	for _, row := range resp.Values {
-		if len(row) > 0 && row[0] == today {
-			entry := TimeEntry{
-				Date:    getStringValue(row, 0),
-				Project: getStringValue(row, 1),
-				Task:    getStringValue(row, 2),
+		if len(row) > 0 {
+			dateStr := getStringValue(row, 0)
+			date, err := time.Parse("2006-01-02", dateStr)
+			if err != nil {
+				continue
 			}
 
-			if len(row) > 3 {
-				if hours, ok := row[3].(float64); ok {
-					entry.Hours = hours
+			if date.After(weekStart) && date.Before(weekEnd) {
+				entry := TimeEntry{
+					Date:    dateStr,
+					Project: getStringValue(row, 1),
+					Task:    getStringValue(row, 2),
 				}
-			}
 
-			entry.Description = getStringValue(row, 4)
-			entry.GitCommits = getStringValue(row, 5)
-			entry.GitPRs = getStringValue(row, 6)
+				if len(row) > 3 {
+					if hours, ok := row[3].(float64); ok {
+						entry.Hours = hours
+					}
+				}
 
-			entries = append(entries, entry)
+				entry.Description = getStringValue(row, 4)
+				entry.GitCommits = getStringValue(row, 5)
+				entry.GitPRs = getStringValue(row, 6)
+
+				entries = append(entries, entry)
+			}
 		}
 	}
 
 	return entries, nil
 }
 
-func (s *SheetsClient) GetWeekEntries() ([]TimeEntry, error) {
-	now := time.Now()
-	weekStart := now.AddDate(0, 0, -int(now.Weekday()))
-	weekEnd := weekStart.AddDate(0, 0, 7)
+// GetEntries returns the entries dated from from's day up to, but not
+// including, to's day.
+func (s *SheetsClient) GetEntries(from, to time.Time) ([]TimeEntry, error) {
+	// Dates are stored as YYYY-MM-DD, which compares correctly as a string
+	first := from.Format("2006-01-02")
+	end := to.Format("2006-01-02")
 
 	resp, err := s.service.Spreadsheets.Values.Get(
 		s.spreadsheetID,
@@ -115,12 +150,11 @@ func (s *SheetsClient) GetWeekEntries() ([]TimeEntry, error) {
 	for _, row := range resp.Values {
 		if len(row) > 0 {
 			dateStr := getStringValue(row, 0)
-			date, err := time.Parse("2006-01-02", dateStr)
-			if err != nil {
+			if _, err := time.Parse("2006-01-02", dateStr); err != nil {
 				continue
 			}
 
-			if date.After(weekStart) && date.Before(weekEnd) {
+			if dateStr >= first && dateStr < end {
 				entry := TimeEntry{
 					Date:    dateStr,
 					Project: getStringValue(row, 1),
@@ -128,8 +162,12 @@ func (s *SheetsClient) GetWeekEntries() ([]TimeEntry, error) {
 				}
 
 				if len(row) > 3 {
-					if hours, ok := row[3].(float64); ok {
+					switch hours := row[3].(type) {
+					case float64:
 						entry.Hours = hours
+					case string:
+						// Values come back formatted unless asked otherwise
+						entry.Hours, _ = strconv.ParseFloat(strings.TrimSpace(hours), 64)
 					}
 				}
 
@@ -0,0 +1,372 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/digitaldrywood/timetracker/internal/activity"
+)
+
+// Rule classifies the activity it matches. Every condition given must
+// hold; a rule without conditions matches everything.
+type Rule struct {
+	Name  string `json:"name"`
+	Match Match  `json:"match"`
+	Set   Set    `json:"set"`
+
+	message *regexp.Regexp
+	days    map[time.Weekday]bool
+	from    int // minutes after midnight
+	to      int
+}
+
+// Match lists a rule's conditions.
+type Match struct {
+	// Repo is a glob matched against the repository, e.g.
+	// "github.com/acme/*", ignoring case
+	Repo string `json:"repo"`
+
+	// Path is a glob matched against a local clone's working tree or any
+	// directory above it, e.g. "~/clients/acme"
+	Path string `json:"path"`
+
+	// Message is a regular expression matched against the title and body
+	Message string `json:"message"`
+
+	// Branch is a glob matched against the branch, e.g. "release/*"
+	Branch string `json:"branch"`
+
+	// Days are weekdays ("mon", "tuesday") the activity happened on
+	Days []string `json:"days"`
+
+	// Time is a local time range such as "09:00-17:00"; "22:00-02:00"
+	// wraps past midnight. A range that starts where it ends, such as
+	// "09:00-09:00", is rejected.
+	Time string `json:"time"`
+}
+
+// Set is what a matching rule assigns. Description is a text/template for
+// the entry's description, executed with DescriptionData.
+type Set struct {
+	Client      string `json:"client"`
+	Project     string `json:"project"`
+	Task        string `json:"task"`
+	Billable    *bool  `json:"billable"`
+	Description string `json:"description"`
+}
+
+// DescriptionData is what a description template can refer to, e.g.
+// "{{.Project}}: {{join .Commits \"; \"}}". Besides join, templates can use
+// list ("a, b and c"), sentence (a capitalized list with a full stop) and
+// bullets (one "- " line each).
+type DescriptionData struct {
+	Date         string
+	Client       string
+	Project      string
+	Task         string
+	Hours        float64
+	Issues       []string // issue keys
+	Commits      []string // commit subjects
+	PullRequests []string // pull request titles
+	Items        []string // titles of everything in the entry
+
+	// Summary is the entry's work with prefixes and ticket noise stripped,
+	// duplicates removed and related commits grouped
+	Summary []string
+}
+
+var templateFuncs = template.FuncMap{
+	"join":     strings.Join,
+	"list":     List,
+	"sentence": sentence,
+	"bullets":  bullets,
+}
+
+// List joins phrases as "a, b and c".
+func List(phrases []string) string {
+	switch len(phrases) {
+	case 0:
+		return ""
+	case 1:
+		return phrases[0]
+	}
+	return strings.Join(phrases[:len(phrases)-1], ", ") + " and " + phrases[len(phrases)-1]
+}
+
+// sentence turns phrases into a sentence: "A, b and c."
+func sentence(phrases []string) string {
+	s := List(phrases)
+	if s == "" {
+		return ""
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	s = string(unicode.ToUpper(r)) + s[size:]
+	if !strings.HasSuffix(s, ".") {
+		s += "."
+	}
+	return s
+}
+
+func bullets(phrases []string) string {
+	if len(phrases) == 0 {
+		return ""
+	}
+	return "- " + strings.Join(phrases, "\n- ")
+}
+
+// RenderDescription executes a description template.
+func RenderDescription(text string, data DescriptionData) (string, error) {
+	tmpl, err := template.New("description").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// Rules is an ordered list of rules.
+type Rules struct {
+	Rules []*Rule `json:"rules"`
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// Load reads a rules file. A missing file means no rules.
+func Load(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Rules{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file %s: %v", path, err)
+	}
+
+	var rules Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse rules file %s: %v", path, err)
+	}
+	if err := rules.compile(); err != nil {
+		return nil, fmt.Errorf("invalid rules file %s: %v", path, err)
+	}
+	return &rules, nil
+}
+
+func (r *Rules) compile() error {
+	for i, rule := range r.Rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		if err := rule.compile(); err != nil {
+			return fmt.Errorf("%s: %v", rule.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r *Rule) compile() error {
+	m := r.Match
+
+	if m.Repo != "" {
+		if _, err := path.Match(m.Repo, ""); err != nil {
+			return fmt.Errorf("invalid repo %q: %v", m.Repo, err)
+		}
+	}
+	if m.Branch != "" {
+		if _, err := path.Match(m.Branch, ""); err != nil {
+			return fmt.Errorf("invalid branch %q: %v", m.Branch, err)
+		}
+	}
+	if m.Path != "" {
+		r.Match.Path = expandHome(m.Path)
+		if _, err := filepath.Match(r.Match.Path, ""); err != nil {
+			return fmt.Errorf("invalid path %q: %v", m.Path, err)
+		}
+	}
+
+	if m.Message != "" {
+		re, err := regexp.Compile(m.Message)
+		if err != nil {
+			return fmt.Errorf("invalid message %q: %v", m.Message, err)
+		}
+		r.message = re
+	}
+
+	if len(m.Days) > 0 {
+		r.days = make(map[time.Weekday]bool)
+		for _, day := range m.Days {
+			weekday, ok := weekdayNames[strings.ToLower(day)]
+			if !ok {
+				return fmt.Errorf("invalid day %q", day)
+			}
+			r.days[weekday] = true
+		}
+	}
+
+	if m.Time != "" {
+		from, to, ok := strings.Cut(m.Time, "-")
+		var err error
+		if !ok {
+			return fmt.Errorf("invalid time %q: want HH:MM-HH:MM", m.Time)
+		}
+		if r.from, err = parseClock(from); err != nil {
+			return fmt.Errorf("invalid time %q: %v", m.Time, err)
+		}
+		if r.to, err = parseClock(to); err != nil {
+			return fmt.Errorf("invalid time %q: %v", m.Time, err)
+		}
+		if r.from == r.to {
+			return fmt.Errorf("invalid time %q: the range is empty", m.Time)
+		}
+	}
+
+	if r.Set.Description != "" {
+		// Executing catches references to fields that don't exist
+		if _, err := RenderDescription(r.Set.Description, DescriptionData{}); err != nil {
+			return fmt.Errorf("invalid description template: %v", err)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the rule applies to item.
+func (r *Rule) Matches(item activity.Item) bool {
+	m := r.Match
+
+	if m.Repo != "" {
+		if ok, _ := path.Match(strings.ToLower(m.Repo), strings.ToLower(item.Repository)); !ok {
+			return false
+		}
+	}
+	if m.Branch != "" {
+		if ok, _ := path.Match(m.Branch, item.Branch); !ok {
+			return false
+		}
+	}
+	if m.Path != "" && !matchesPath(m.Path, item.Path) {
+		return false
+	}
+	if r.message != nil && !r.message.MatchString(item.Title) && !r.message.MatchString(item.Body) {
+		return false
+	}
+
+	local := item.Time.Local()
+	if r.days != nil && !r.days[local.Weekday()] {
+		return false
+	}
+	if m.Time != "" {
+		minute := local.Hour()*60 + local.Minute()
+		if r.from <= r.to && (minute < r.from || minute >= r.to) {
+			return false
+		}
+		if r.from > r.to && minute < r.from && minute >= r.to {
+			return false
+		}
+	}
+	return true
+}
+
+// Matching returns the rules that apply to item, in order.
+func (r *Rules) Matching(item activity.Item) []*Rule {
+	var matching []*Rule
+	for _, rule := range r.Rules {
+		if rule.Matches(item) {
+			matching = append(matching, rule)
+		}
+	}
+	return matching
+}
+
+// Classify applies the matching rules to item in order. Each field is set
+// by the first matching rule that sets it, and values already on the item
+// are kept.
+func (r *Rules) Classify(item activity.Item) activity.Item {
+	for _, rule := range r.Matching(item) {
+		set := rule.Set
+		if item.Client == "" {
+			item.Client = set.Client
+		}
+		if item.Project == "" {
+			item.Project = set.Project
+		}
+		if item.Task == "" {
+			item.Task = set.Task
+		}
+		if item.Billable == nil && set.Billable != nil {
+			billable := *set.Billable
+			item.Billable = &billable
+		}
+		if item.Description == "" {
+			item.Description = set.Description
+		}
+	}
+	return item
+}
+
+// Apply classifies every item.
+func (r *Rules) Apply(items []activity.Item) []activity.Item {
+	if r == nil || len(r.Rules) == 0 {
+		return items
+	}
+	classified := make([]activity.Item, len(items))
+	for i, item := range items {
+		classified[i] = r.Classify(item)
+	}
+	return classified
+}
+
+// matchesPath reports whether dir or any directory above it matches the
+// glob.
+func matchesPath(pattern, dir string) bool {
+	if dir == "" {
+		return false
+	}
+	for dir = filepath.Clean(dir); ; dir = filepath.Dir(dir) {
+		if ok, _ := filepath.Match(pattern, dir); ok {
+			return true
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return false
+		}
+	}
+}
+
+func parseClock(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "24:00" {
+		return 24 * 60, nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
@@ -0,0 +1,170 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/digitaldrywood/timetracker/internal/activity"
+)
+
+// load writes a rules file and loads it.
+func load(t *testing.T, content string) (*Rules, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return Load(path)
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "valid", content: `{"rules": [{"match": {"repo": "github.com/acme/*", "days": ["mon", "Friday"], "time": "09:00-17:00"}}]}`},
+		{name: "invalid json", content: `{"rules": [`, wantErr: "failed to parse"},
+		{name: "invalid glob", content: `{"rules": [{"name": "acme", "match": {"repo": "[acme"}}]}`, wantErr: "acme: invalid repo"},
+		{name: "invalid regexp", content: `{"rules": [{"match": {"message": "("}}]}`, wantErr: "rule 1: invalid message"},
+		{name: "invalid day", content: `{"rules": [{"match": {"days": ["someday"]}}]}`, wantErr: "invalid day"},
+		{name: "invalid time", content: `{"rules": [{"match": {"time": "9am"}}]}`, wantErr: "invalid time"},
+		{name: "empty time range", content: `{"rules": [{"match": {"time": "09:00-09:00"}}]}`, wantErr: "range is empty"},
+		{name: "unknown template field", content: `{"rules": [{"set": {"description": "{{.Nope}}"}}]}`, wantErr: "invalid description template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := load(t, tt.content)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Load: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	rules, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil || len(rules.Rules) != 0 {
+		t.Errorf("missing file = %v, %v; want no rules", rules, err)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	// A Tuesday afternoon
+	at := time.Date(2026, 1, 13, 14, 30, 0, 0, time.Local)
+	item := activity.Item{
+		Repository: "github.com/Acme/website",
+		Branch:     "feature/login",
+		Path:       "/home/me/clients/acme/website",
+		Title:      "Fix login redirect",
+		Body:       "Refs ABC-123",
+		Time:       at,
+	}
+
+	tests := []struct {
+		name  string
+		match Match
+		want  bool
+	}{
+		{name: "no conditions", want: true},
+		{name: "repo glob ignores case", match: Match{Repo: "github.com/acme/*"}, want: true},
+		{name: "other repo", match: Match{Repo: "github.com/other/*"}},
+		{name: "branch glob", match: Match{Branch: "feature/*"}, want: true},
+		{name: "other branch", match: Match{Branch: "release/*"}},
+		{name: "parent path", match: Match{Path: "/home/me/clients/acme"}, want: true},
+		{name: "other path", match: Match{Path: "/home/me/clients/other"}},
+		{name: "message in title", match: Match{Message: `(?i)login`}, want: true},
+		{name: "message in body", match: Match{Message: `ABC-\d+`}, want: true},
+		{name: "message missing", match: Match{Message: `XYZ-\d+`}},
+		{name: "weekday", match: Match{Days: []string{"tue", "wed"}}, want: true},
+		{name: "weekend", match: Match{Days: []string{"sat", "sun"}}},
+		{name: "within hours", match: Match{Time: "09:00-17:00"}, want: true},
+		{name: "outside hours", match: Match{Time: "17:00-24:00"}},
+		{name: "wrapping range", match: Match{Time: "22:00-15:00"}, want: true},
+		{name: "all conditions", match: Match{Repo: "github.com/acme/*", Branch: "feature/*", Days: []string{"tue"}}, want: true},
+		{name: "one condition fails", match: Match{Repo: "github.com/acme/*", Branch: "release/*"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &Rule{Match: tt.match}
+			if err := rule.compile(); err != nil {
+				t.Fatalf("compile: %v", err)
+			}
+			if got := rule.Matches(item); got != tt.want {
+				t.Errorf("Matches = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	rules, err := load(t, `{"rules": [
+		{"match": {"repo": "github.com/acme/*", "message": "(?i)hotfix"}, "set": {"task": "Support", "billable": false}},
+		{"match": {"repo": "github.com/acme/*"}, "set": {"client": "Acme", "project": "Website", "task": "Development", "billable": true}},
+		{"set": {"client": "Internal"}}
+	]}`)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	items := rules.Apply([]activity.Item{
+		{Repository: "github.com/acme/website", Title: "Add search"},
+		{Repository: "github.com/acme/website", Title: "Hotfix checkout"},
+		{Repository: "github.com/acme/website", Title: "Ruled by the source", Task: "Meeting"},
+		{Repository: "github.com/me/dotfiles", Title: "Tweak prompt"},
+	})
+
+	tests := []struct {
+		client, project, task string
+		billable              *bool
+	}{
+		{"Acme", "Website", "Development", boolPtr(true)},
+		{"Acme", "Website", "Support", boolPtr(false)},
+		{"Acme", "Website", "Meeting", boolPtr(true)},
+		{"Internal", "", "", nil},
+	}
+	for i, want := range tests {
+		got := items[i]
+		if got.Client != want.client || got.Project != want.project || got.Task != want.task {
+			t.Errorf("item %d = %q/%q/%q, want %q/%q/%q", i, got.Client, got.Project, got.Task, want.client, want.project, want.task)
+		}
+		if (got.Billable == nil) != (want.billable == nil) || got.Billable != nil && *got.Billable != *want.billable {
+			t.Errorf("item %d billable = %v, want %v", i, got.Billable, want.billable)
+		}
+	}
+
+	var none *Rules
+	if got := none.Apply(items); len(got) != len(items) {
+		t.Errorf("Apply without rules returned %d items, want %d", len(got), len(items))
+	}
+}
+
+func TestList(t *testing.T) {
+	tests := []struct {
+		phrases []string
+		want    string
+	}{
+		{nil, ""},
+		{[]string{"a"}, "a"},
+		{[]string{"a", "b"}, "a and b"},
+		{[]string{"a", "b", "c"}, "a, b and c"},
+	}
+	for _, tt := range tests {
+		if got := List(tt.phrases); got != tt.want {
+			t.Errorf("List(%q) = %q, want %q", tt.phrases, got, tt.want)
+		}
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
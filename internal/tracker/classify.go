@@ -0,0 +1,61 @@
+package tracker
+
+import (
+	"sort"
+
+	"github.com/digitaldrywood/timetracker/internal/activity"
+	"github.com/digitaldrywood/timetracker/internal/google"
+)
+
+// project is the project an item is logged against: the one a rule set,
+// or its repository.
+func project(item activity.Item) string {
+	if item.Project != "" {
+		return item.Project
+	}
+	return item.Repository
+}
+
+// groupByProject groups items by project, returning the projects sorted.
+func groupByProject(items []activity.Item) ([]string, map[string][]activity.Item) {
+	byProject := make(map[string][]activity.Item)
+	var projects []string
+	for _, item := range items {
+		p := project(item)
+		if _, exists := byProject[p]; !exists {
+			projects = append(projects, p)
+		}
+		byProject[p] = append(byProject[p], item)
+	}
+	sort.Strings(projects)
+	return projects, byProject
+}
+
+// billable reports whether an item's time is billable; it is unless a rule
+// says otherwise.
+func billable(item activity.Item) bool {
+	return item.Billable == nil || *item.Billable
+}
+
+// classify applies the rules' classification of an entry's items to it:
+// the first client and task set on any of them and the first item's
+// billable flag, then prefills it from past entries and describes it.
+func (t *Tracker) classify(entry *google.TimeEntry, items []activity.Item, past history) {
+	entry.Billable = len(items) == 0 || billable(items[0])
+
+	task := ""
+	for _, item := range items {
+		if entry.Client == "" {
+			entry.Client = item.Client
+		}
+		if task == "" {
+			task = item.Task
+		}
+	}
+	if task != "" {
+		entry.Task = task
+	}
+	t.prefill(entry, items, past)
+	entry.TaskType = TaskType(entry.Task)
+	t.describe(entry, items, nil)
+}
@@ -0,0 +1,51 @@
+package tracker
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/digitaldrywood/timetracker/internal/github"
+)
+
+// ParseDateRange reads the days a command should cover: "today",
+// "yesterday", a date like 2025-01-31 or an inclusive range like
+// 2025-01-27..2025-01-31. An empty value means today. It returns the first
+// and last day at local midnight.
+func ParseDateRange(value string, now time.Time) (time.Time, time.Time, error) {
+	first, last, isRange := strings.Cut(strings.TrimSpace(value), "..")
+	if !isRange {
+		last = first
+	}
+
+	from, err := parseDay(first, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := parseDay(last, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("date range %q ends before it starts", value)
+	}
+
+	return from, to, nil
+}
+
+func parseDay(value string, now time.Time) (time.Time, error) {
+	today, _ := github.DayRange(now)
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+
+	day, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(value), now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD, today or yesterday", value)
+	}
+	return day, nil
+}
@@ -0,0 +1,194 @@
+package tracker
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/digitaldrywood/timetracker/internal/activity"
+	"github.com/digitaldrywood/timetracker/internal/google"
+	"github.com/digitaldrywood/timetracker/internal/rules"
+)
+
+// DefaultDescriptionTemplates describe entries by task; "default" covers
+// tasks without a template of their own.
+var DefaultDescriptionTemplates = map[string]string{
+	"default":      "{{sentence .Summary}}",
+	"Code Review":  "{{if .Summary}}Reviewed {{list .Summary}}.{{end}}",
+	"Issue Triage": "{{if .Summary}}Triaged {{list .Summary}}.{{end}}",
+}
+
+var (
+	// Squash-merge PR references such as "(#123)" and bracketed ticket
+	// keys such as "[ABC-123]", stripped even without issue patterns
+	pullRequestRef = regexp.MustCompile(`\(#[0-9]+\)`)
+	ticketRef      = regexp.MustCompile(`\[[A-Z][A-Z0-9]*-[0-9]+\]`)
+
+	// "fixup!", "squash!" and "WIP" markers, and "Merge branch ..." subjects
+	commitNoise = regexp.MustCompile(`(?i)^(?:(?:fixup|squash|amend)!\s*|wip\b[:\s]*)+|^merge (?:branch|pull request|remote-tracking branch)\b.*$`)
+
+	emptyBrackets = regexp.MustCompile(`\[\s*\]|\(\s*\)|\{\s*\}`)
+	spaces        = regexp.MustCompile(`\s+`)
+)
+
+// Characters left dangling at either end once noise is removed
+const danglingPunctuation = " \t:;,-–—|/#."
+
+// describe fills in an entry's Description from a template: the first one
+// a rule set on its items, or the one for its task. The template is
+// executed with the entry's work summarized; an empty result keeps the
+// description the entry already had.
+func (t *Tracker) describe(entry *google.TimeEntry, items []activity.Item, issues []string) {
+	text := ""
+	for _, item := range items {
+		if item.Description != "" {
+			text = item.Description
+			break
+		}
+	}
+	if text == "" {
+		if text = t.descriptionTemplates[entry.Task]; text == "" {
+			text = t.descriptionTemplates["default"]
+		}
+	}
+	if text == "" {
+		return
+	}
+
+	data := rules.DescriptionData{
+		Date:    entry.Date,
+		Client:  entry.Client,
+		Project: entry.Project,
+		Task:    entry.Task,
+		Hours:   entry.Hours,
+		Issues:  issues,
+		Summary: t.summarize(items),
+	}
+	for _, item := range items {
+		switch item.Kind {
+		case activity.KindCommit:
+			data.Commits = append(data.Commits, item.Title)
+		case activity.KindPullRequest:
+			data.PullRequests = append(data.PullRequests, item.Title)
+		}
+		if !slices.Contains(data.Items, item.Title) {
+			data.Items = append(data.Items, item.Title)
+		}
+	}
+
+	// Templates are checked when they're loaded
+	if description, err := rules.RenderDescription(text, data); err == nil && description != "" {
+		entry.Description = description
+	}
+}
+
+// summarize turns the titles of an entry's commits, pull requests, reviews,
+// issues and meetings into short phrases: prefixes and ticket noise are
+// stripped, duplicates dropped and commits sharing a Conventional Commit
+// scope joined, e.g. "add login and fix token refresh (auth)".
+func (t *Tracker) summarize(items []activity.Item) []string {
+	var (
+		scopes  []string
+		byScope = make(map[string][]string)
+		seen    = make(map[string]bool)
+	)
+	for _, item := range items {
+		switch item.Kind {
+		case activity.KindWorkInProgress, activity.KindSession:
+			// Their titles describe files and branches, not the work
+			continue
+		}
+
+		scope := ""
+		if match := conventionalScope.FindStringSubmatch(item.Title); match != nil {
+			scope = strings.ToLower(strings.TrimSpace(match[1]))
+		}
+
+		phrase := t.cleanTitle(item.Title)
+		key := strings.ToLower(phrase)
+		if phrase == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if _, exists := byScope[scope]; !exists {
+			scopes = append(scopes, scope)
+		}
+		byScope[scope] = append(byScope[scope], phrase)
+	}
+
+	var summary []string
+	for _, scope := range scopes {
+		phrases := byScope[scope]
+		if scope == "" || len(phrases) == 1 {
+			summary = append(summary, phrases...)
+			continue
+		}
+		summary = append(summary, rules.List(phrases)+" ("+scope+")")
+	}
+	return summary
+}
+
+// A Conventional Commit subject's scope: "feat(auth): ..."
+var conventionalScope = regexp.MustCompile(`^\s*[A-Za-z]+\(([^)]+)\)!?:`)
+
+// A revert, as git words it ("Revert \"feat: add export\"") or as a
+// Conventional Commit ("revert: feat: add export")
+var revertSubject = regexp.MustCompile(`(?i)^\s*revert(?:\s+"(.*)"\s*$|(?:\([^)]*\))?!?:\s*(.*)$)`)
+
+// cleanTitle strips a title down to the work it describes: no Conventional
+// Commit prefix, issue keys, PR references or WIP markers, and lower case
+// unless it starts with an acronym. A revert keeps saying so, as in
+// "revert add export".
+func (t *Tracker) cleanTitle(title string) string {
+	if match := revertSubject.FindStringSubmatch(title); match != nil {
+		if reverted := t.cleanTitle(match[1] + match[2]); reverted != "" {
+			return "revert " + reverted
+		}
+		return ""
+	}
+
+	s := commitNoise.ReplaceAllString(strings.TrimSpace(title), "")
+	s = t.stripCommitType(s)
+	s = pullRequestRef.ReplaceAllString(s, "")
+	s = ticketRef.ReplaceAllString(s, "")
+	for _, re := range t.issuePatterns {
+		s = re.ReplaceAllString(s, "")
+	}
+	s = emptyBrackets.ReplaceAllString(s, "")
+	s = spaces.ReplaceAllString(s, " ")
+	s = strings.Trim(s, danglingPunctuation)
+
+	// Removing a ticket key can leave a second prefix behind, as in
+	// "ABC-1: fix: typo"
+	if revertSubject.MatchString(s) {
+		return t.cleanTitle(s)
+	}
+	s = strings.Trim(t.stripCommitType(s), danglingPunctuation)
+	if s == "" {
+		return ""
+	}
+
+	first, size := utf8.DecodeRuneInString(s)
+	second, _ := utf8.DecodeRuneInString(s[size:])
+	if unicode.IsUpper(first) && !unicode.IsUpper(second) {
+		s = string(unicode.ToLower(first)) + s[size:]
+	}
+	return s
+}
+
+// stripCommitType removes a Conventional Commit prefix whose type is
+// known, leaving subjects such as "Login: handle expiry" alone.
+func (t *Tracker) stripCommitType(s string) string {
+	match := conventionalCommit.FindStringSubmatchIndex(s)
+	if match == nil {
+		return s
+	}
+	commitType := strings.ToLower(s[match[2]:match[3]])
+	if _, ok := t.taskTypes[commitType]; !ok && commitType != "style" {
+		return s
+	}
+	return s[match[1]:]
+}
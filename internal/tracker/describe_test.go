@@ -0,0 +1,31 @@
+package tracker
+
+import "testing"
+
+func TestCleanTitle(t *testing.T) {
+	tr, err := NewTracker(nil, nil, Options{IssuePatterns: []string{`[A-Z]+-[0-9]+`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"feat: Add export", "add export"},
+		{"fix(auth): handle expiry (#42)", "handle expiry"},
+		{"ABC-1: fix: typo", "typo"},
+		{"[ABC-12] Update README", "update README"},
+		{"fixup! WIP: tidy up", "tidy up"},
+		{"Login: handle expiry", "login: handle expiry"},
+		{"Merge branch 'main' into feature", ""},
+		{"revert: feat: add export", "revert add export"},
+		{`Revert "feat: add export"`, "revert add export"},
+		{"ABC-1: revert: fix typo", "revert fix typo"},
+	}
+	for _, tt := range tests {
+		if got := tr.cleanTitle(tt.title); got != tt.want {
+			t.Errorf("cleanTitle(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
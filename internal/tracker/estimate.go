@@ -0,0 +1,130 @@
+package tracker
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/digitaldrywood/timetracker/internal/activity"
+)
+
+const (
+	// Activity further apart than sessionGap is treated as separate
+	// sittings rather than continuous work.
+	sessionGap = 45 * time.Minute
+
+	// Each sitting starts before its first recorded event; reviewers read
+	// the diff before submitting anything.
+	sessionLeadIn = 15 * time.Minute
+
+	// The most extra lead-in a large commit adds to its session.
+	maxChangeLeadIn = 45 * time.Minute
+)
+
+// estimateHours turns activity timestamps into a rough number of hours.
+// Timestamps are grouped into sessions, each session counts from its lead-in
+// to its last event, and the total is rounded to the nearest quarter hour.
+func estimateHours(timestamps []time.Time) float64 {
+	events := make([]estimateEvent, len(timestamps))
+	for i, ts := range timestamps {
+		events[i] = estimateEvent{at: ts, leadIn: sessionLeadIn}
+	}
+	return estimateSessions(events)
+}
+
+// estimateWorkHours estimates like estimateHours, but weights each
+// session's lead-in by the size of the commit that opens it: a one-line
+// fix needs little work before it, a two thousand line change a lot more.
+// Work in progress and reflog sessions count as activity at both ends of
+// their time range.
+func estimateWorkHours(items []activity.Item) float64 {
+	var events []estimateEvent
+	for _, item := range items {
+		if item.Kind == activity.KindWorkInProgress || item.Kind == activity.KindSession {
+			events = append(events, estimateEvent{at: item.Time, leadIn: sessionLeadIn})
+			if item.End.After(item.Time) {
+				events = append(events, estimateEvent{at: item.End, leadIn: sessionLeadIn})
+			}
+			continue
+		}
+
+		events = append(events, estimateEvent{
+			at:     item.Time,
+			leadIn: sessionLeadIn + changeLeadIn(item.Additions+item.Deletions),
+		})
+	}
+	return estimateSessions(events)
+}
+
+// changeLeadIn grows logarithmically with the lines changed: about 15
+// minutes for 50 lines, 30 for 150, capped at maxChangeLeadIn.
+func changeLeadIn(lines int) time.Duration {
+	if lines <= 0 {
+		return 0
+	}
+	d := time.Duration(math.Log2(1+float64(lines)/50) * float64(15*time.Minute))
+	if d > maxChangeLeadIn {
+		return maxChangeLeadIn
+	}
+	return d
+}
+
+type estimateEvent struct {
+	at     time.Time
+	leadIn time.Duration // work assumed before the event when it opens a session
+}
+
+func estimateSessions(events []estimateEvent) float64 {
+	if len(events) == 0 {
+		return 0
+	}
+
+	sorted := make([]estimateEvent, len(events))
+	copy(sorted, events)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].at.Before(sorted[j].at) })
+
+	var total time.Duration
+	start := sorted[0]
+	last := sorted[0].at
+	for _, event := range sorted[1:] {
+		if event.at.Sub(last) > sessionGap {
+			total += last.Sub(start.at) + start.leadIn
+			start = event
+		}
+		last = event.at
+	}
+	total += last.Sub(start.at) + start.leadIn
+
+	return roundQuarterHour(total)
+}
+
+func roundQuarterHour(d time.Duration) float64 {
+	hours := math.Round(d.Hours()*4) / 4
+	if hours < 0.25 {
+		return 0.25
+	}
+	return hours
+}
+
+// meetingHours totals the time covered by meetings, counting overlapping
+// meetings once, rounded to the nearest quarter hour.
+func meetingHours(meetings []activity.Item) float64 {
+	sorted := append([]activity.Item(nil), meetings...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	var (
+		total     time.Duration
+		coveredTo time.Time
+	)
+	for _, meeting := range sorted {
+		start := meeting.Time
+		if start.Before(coveredTo) {
+			start = coveredTo
+		}
+		if meeting.End.After(start) {
+			total += meeting.End.Sub(start)
+			coveredTo = meeting.End
+		}
+	}
+	return roundQuarterHour(total)
+}
@@ -0,0 +1,61 @@
+package tracker
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+
+	"github.com/digitaldrywood/timetracker/internal/activity"
+)
+
+// Where issue keys are written in suggested entries
+const (
+	IssueColumnTask        = "task"
+	IssueColumnDescription = "description"
+)
+
+// compileIssuePatterns compiles the configured issue key patterns.
+func compileIssuePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid issue pattern %q: %v", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// issueKeys returns the issue keys mentioned by an item, in the order they
+// appear in its title, message body and branch name. A pattern with a
+// capture group contributes the group rather than the whole match.
+func (t *Tracker) issueKeys(item activity.Item) []string {
+	type found struct {
+		at  int
+		key string
+	}
+
+	var keys []string
+	for _, text := range []string{item.Title, item.Body, item.Branch} {
+		var matches []found
+		for _, re := range t.issuePatterns {
+			for _, loc := range re.FindAllStringSubmatchIndex(text, -1) {
+				start, end := loc[0], loc[1]
+				if len(loc) > 3 && loc[2] >= 0 {
+					start, end = loc[2], loc[3]
+				}
+				matches = append(matches, found{at: start, key: text[start:end]})
+			}
+		}
+		sort.SliceStable(matches, func(i, j int) bool { return matches[i].at < matches[j].at })
+
+		for _, match := range matches {
+			if match.key != "" && !slices.Contains(keys, match.key) {
+				keys = append(keys, match.key)
+			}
+		}
+	}
+	return keys
+}
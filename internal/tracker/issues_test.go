@@ -0,0 +1,106 @@
+package tracker
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/digitaldrywood/timetracker/internal/activity"
+)
+
+func TestIssueKeys(t *testing.T) {
+	tr, err := NewTracker(nil, nil, Options{IssuePatterns: []string{`[A-Z]+-[0-9]+`, `#([0-9]+)`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		item activity.Item
+		want []string
+	}{
+		{
+			name: "title",
+			item: activity.Item{Title: "ABC-1: fix login"},
+			want: []string{"ABC-1"},
+		},
+		{
+			name: "body",
+			item: activity.Item{Title: "fix login", Body: "Closes ABC-2"},
+			want: []string{"ABC-2"},
+		},
+		{
+			name: "branch",
+			item: activity.Item{Title: "fix login", Branch: "feature/ABC-3-login"},
+			want: []string{"ABC-3"},
+		},
+		{
+			name: "title, then body, then branch",
+			item: activity.Item{Title: "ABC-2 and ABC-1", Body: "See XYZ-9", Branch: "feature/ABC-3"},
+			want: []string{"ABC-2", "ABC-1", "XYZ-9", "ABC-3"},
+		},
+		{
+			name: "duplicates are listed once",
+			item: activity.Item{Title: "ABC-1: fix login", Body: "Fixes ABC-1", Branch: "ABC-1-login"},
+			want: []string{"ABC-1"},
+		},
+		{
+			name: "patterns are merged by position",
+			item: activity.Item{Title: "#42 follow-up to ABC-1"},
+			want: []string{"42", "ABC-1"},
+		},
+		{
+			name: "no keys",
+			item: activity.Item{Title: "fix login", Branch: "main"},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tr.issueKeys(tt.item); !slices.Equal(got, tt.want) {
+				t.Errorf("issueKeys = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupHours(t *testing.T) {
+	tr, err := NewTracker(nil, nil, Options{IssuePatterns: []string{`ABC-[0-9]+`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := time.Date(2026, 1, 13, 9, 0, 0, 0, time.Local)
+	items := []activity.Item{
+		{Kind: activity.KindCommit, Repository: "github.com/acme/website", Title: "feat: ABC-1 export", Time: at},
+		{Kind: activity.KindCommit, Repository: "github.com/acme/website", Title: "feat: ABC-1 export csv", Time: at.Add(time.Hour)},
+		{Kind: activity.KindCommit, Repository: "github.com/acme/website", Title: "feat: ABC-2 search", Time: at.Add(2 * time.Hour)},
+		{Kind: activity.KindCommit, Repository: "github.com/acme/docs", Title: "docs: ABC-3 guide", Time: at},
+		{Kind: activity.KindCommit, Repository: "github.com/acme/docs", Title: "docs: update guide", Time: at.Add(30 * time.Minute)},
+	}
+
+	entries := tr.generateSuggestedEntries(items, "2026-01-13", nil)
+	if len(entries) != 4 {
+		t.Fatalf("entries = %d, want 4: %+v", len(entries), entries)
+	}
+
+	hours := make(map[string]float64)
+	for _, entry := range entries {
+		if entry.Hours <= 0 {
+			t.Errorf("%s %s has no hours", entry.Project, entry.Task)
+		}
+		hours[entry.Project] += entry.Hours
+	}
+	for _, project := range []string{"github.com/acme/website", "github.com/acme/docs"} {
+		var work []activity.Item
+		for _, item := range items {
+			if item.Repository == project {
+				work = append(work, item)
+			}
+		}
+		// Each share is rounded to a quarter hour on its own
+		if diff := hours[project] - estimateWorkHours(work); diff > 0.25 || diff < -0.25 {
+			t.Errorf("%s hours = %v, want about %v", project, hours[project], estimateWorkHours(work))
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package tracker
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/digitaldrywood/timetracker/internal/activity"
+	"github.com/digitaldrywood/timetracker/internal/google"
+)
+
+// DefaultHistoryWeeks is how far back suggestion defaults are learned from.
+const DefaultHistoryWeeks = 12
+
+// PastEntry is an entry logged before, to learn suggestion defaults from.
+type PastEntry struct {
+	Date       string
+	Repository string // what the suggestion was built for; the project when unknown
+	Project    string
+	Task       string
+	Hours      float64
+	Activity   int // commits, pull requests and other items the entry listed
+}
+
+// History supplies the entries logged from from's day up to, but not
+// including, to's day.
+type History interface {
+	PastEntries(from, to time.Time) ([]PastEntry, error)
+}
+
+// SheetsHistory learns from the entries in the sheet. The sheet doesn't
+// record what a suggestion was built for, so its entries are matched to
+// repositories by project.
+func SheetsHistory(sheets *google.SheetsClient) History {
+	return sheetsHistory{sheets: sheets}
+}
+
+type sheetsHistory struct {
+	sheets *google.SheetsClient
+}
+
+func (h sheetsHistory) PastEntries(from, to time.Time) ([]PastEntry, error) {
+	entries, err := h.sheets.GetEntries(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	past := make([]PastEntry, len(entries))
+	for i, entry := range entries {
+		past[i] = PastEntry{
+			Date:       entry.Date,
+			Repository: entry.Project,
+			Project:    entry.Project,
+			Task:       entry.Task,
+			Hours:      entry.Hours,
+			Activity:   Activity(entry),
+		}
+	}
+	return past, nil
+}
+
+// Activity counts the items an entry lists in its commits and PRs columns.
+func Activity(entry google.TimeEntry) int {
+	count := 0
+	for _, column := range []string{entry.GitCommits, entry.GitPRs} {
+		for _, line := range strings.Split(column, "\n") {
+			if strings.HasPrefix(strings.TrimSpace(line), "- ") {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// history is the past entries to learn from, by lower-cased repository.
+type history map[string][]PastEntry
+
+// loadHistory reads the entries logged in the weeks before a day.
+func (t *Tracker) loadHistory(before time.Time) (history, error) {
+	if t.history == nil {
+		return nil, nil
+	}
+
+	entries, err := t.history.PastEntries(before.AddDate(0, 0, -7*t.historyWeeks), before)
+	if err != nil {
+		return nil, err
+	}
+
+	past := make(history)
+	for _, entry := range entries {
+		if entry.Project == "" {
+			continue
+		}
+		if entry.Repository == "" {
+			entry.Repository = entry.Project
+		}
+		// Keys belong to the issue worked on that day, not to the task
+		entry.Task = t.stripIssueKeys(entry.Task)
+		key := strings.ToLower(entry.Repository)
+		past[key] = append(past[key], entry)
+	}
+	return past, nil
+}
+
+// stripIssueKeys removes the keys writeIssueKeys puts in front of a task,
+// as in "ABC-1, ABC-2: Development".
+func (t *Tracker) stripIssueKeys(task string) string {
+	keys, rest, ok := strings.Cut(task, ": ")
+	if !ok {
+		return task
+	}
+	for _, key := range strings.Split(keys, ", ") {
+		if !t.isIssueKey(key) {
+			return task
+		}
+	}
+	return rest
+}
+
+// TaskTypeOf is the task_type of a task as it's logged, leaving out the
+// issue keys in front of it.
+func (t *Tracker) TaskTypeOf(task string) string {
+	return TaskType(t.stripIssueKeys(task))
+}
+
+// isIssueKey reports whether an issue pattern matches all of s.
+func (t *Tracker) isIssueKey(s string) bool {
+	for _, re := range t.issuePatterns {
+		for _, loc := range re.FindAllStringSubmatchIndex(s, -1) {
+			start, end := loc[0], loc[1]
+			if len(loc) > 3 && loc[2] >= 0 {
+				start, end = loc[2], loc[3]
+			}
+			if start == 0 && end == len(s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// prefill replaces a suggestion's project, task and hours with what was
+// most often logged for the same repository, counting entries from the
+// same weekday and with a similar amount of activity more. A project or
+// task a rule set is kept, and so are a meeting's hours. Confidence is
+// the share of the votes the choices got, discounted while there are few
+// entries to go on.
+func (t *Tracker) prefill(entry *google.TimeEntry, items []activity.Item, past history) {
+	entry.Repository = entry.Project
+
+	var ruledProject, ruledTask, meetings bool
+	meetings = len(items) > 0
+	for _, item := range items {
+		ruledProject = ruledProject || item.Project != ""
+		ruledTask = ruledTask || item.Task != ""
+		meetings = meetings && item.Kind == activity.KindMeeting
+	}
+
+	var candidates []PastEntry
+	for _, e := range past[strings.ToLower(entry.Repository)] {
+		if !ruledProject || strings.EqualFold(e.Project, entry.Project) {
+			candidates = append(candidates, e)
+		}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+
+	day, _ := time.Parse("2006-01-02", entry.Date)
+	volume := volumeBucket(Activity(*entry))
+	weight := func(e PastEntry) float64 {
+		w := 1.0
+		if d, err := time.Parse("2006-01-02", e.Date); err == nil && d.Weekday() == day.Weekday() {
+			w++
+		}
+		if volumeBucket(e.Activity) == volume {
+			w++
+		}
+		return w
+	}
+
+	projects := make(map[string]float64)
+	for _, e := range candidates {
+		projects[e.Project] += weight(e)
+	}
+	project, confidence := mostVoted(projects)
+
+	if !ruledTask {
+		// A generic suggestion takes whatever was logged; a specific one
+		// only another name for the same kind of work
+		tasks := make(map[string]float64)
+		for _, e := range candidates {
+			if e.Project == project && (entry.Task == DefaultTask || TaskType(e.Task) == TaskType(entry.Task)) {
+				tasks[e.Task] += weight(e)
+			}
+		}
+		if len(tasks) > 0 {
+			task, share := mostVoted(tasks)
+			entry.Task = task
+			confidence *= share
+		}
+	}
+
+	if !meetings {
+		var samples []PastEntry
+		for _, e := range candidates {
+			if e.Project == project && e.Task == entry.Task && e.Hours > 0 && volumeBucket(e.Activity) == volume {
+				samples = append(samples, e)
+			}
+		}
+		if len(samples) > 0 {
+			entry.Hours = weightedMedianHours(samples, weight)
+		}
+	}
+
+	n := float64(len(candidates))
+	entry.Project = project
+	entry.Confidence = confidence * n / (n + 2)
+}
+
+// volumeBucket groups days by how much activity an entry lists: none, a
+// little, a fair amount or a lot.
+func volumeBucket(count int) int {
+	switch {
+	case count == 0:
+		return 0
+	case count <= 2:
+		return 1
+	case count <= 6:
+		return 2
+	}
+	return 3
+}
+
+// mostVoted returns the value with the most votes and its share of them;
+// ties go to the value that sorts first.
+func mostVoted(votes map[string]float64) (string, float64) {
+	var (
+		best        string
+		most, total float64
+	)
+	for value, v := range votes {
+		total += v
+		if v > most || v == most && value < best {
+			best, most = value, v
+		}
+	}
+	if total == 0 {
+		return best, 0
+	}
+	return best, most / total
+}
+
+// weightedMedianHours returns the hours half the weight of the samples is
+// at or below, rounded to the quarter hour.
+func weightedMedianHours(samples []PastEntry, weight func(PastEntry) float64) float64 {
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Hours < samples[j].Hours })
+
+	var total float64
+	for _, s := range samples {
+		total += weight(s)
+	}
+
+	var sum float64
+	for _, s := range samples {
+		if sum += weight(s); sum >= total/2 {
+			return roundQuarterHour(time.Duration(s.Hours * float64(time.Hour)))
+		}
+	}
+	return roundQuarterHour(time.Duration(samples[len(samples)-1].Hours * float64(time.Hour)))
+}
@@ -0,0 +1,140 @@
+package tracker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/digitaldrywood/timetracker/internal/activity"
+	"github.com/digitaldrywood/timetracker/internal/google"
+)
+
+// stubHistory returns fixed past entries.
+type stubHistory []PastEntry
+
+func (h stubHistory) PastEntries(from, to time.Time) ([]PastEntry, error) {
+	return h, nil
+}
+
+func TestPrefill(t *testing.T) {
+	const repo = "github.com/acme/website"
+	// 2026-01-13 is a Tuesday, like the 6th; the 7th is a Wednesday
+	commit := activity.Item{Kind: activity.KindCommit, Repository: repo, Title: "fix: login"}
+
+	tests := []struct {
+		name           string
+		history        History // nil turns learning off
+		items          []activity.Item
+		task           string
+		wantProject    string
+		wantTask       string
+		wantHours      float64
+		wantConfidence float64
+	}{
+		{
+			name: "history hit",
+			history: stubHistory{
+				{Date: "2026-01-06", Repository: repo, Project: "Acme Website", Task: "Bug Fix", Hours: 2, Activity: 1},
+				{Date: "2026-01-07", Repository: repo, Project: "Acme Website", Task: "Bug Fix", Hours: 3, Activity: 1},
+			},
+			task:           "Bug Fix",
+			wantProject:    "Acme Website",
+			wantTask:       "Bug Fix",
+			wantHours:      2,
+			wantConfidence: 0.5, // all the votes, but only two entries
+		},
+		{
+			name:           "history miss",
+			history:        stubHistory{{Date: "2026-01-06", Repository: "github.com/acme/api", Project: "Acme API", Task: "Bug Fix", Hours: 2}},
+			task:           "Bug Fix",
+			wantProject:    repo,
+			wantTask:       "Bug Fix",
+			wantHours:      1,
+			wantConfidence: 0,
+		},
+		{
+			name: "ties go to the project that sorts first",
+			history: stubHistory{
+				{Date: "2026-01-06", Repository: repo, Project: "Globex", Task: "Bug Fix", Hours: 4, Activity: 1},
+				{Date: "2026-01-06", Repository: repo, Project: "Acme", Task: "Bug Fix", Hours: 2, Activity: 1},
+			},
+			task:           "Bug Fix",
+			wantProject:    "Acme",
+			wantTask:       "Bug Fix",
+			wantHours:      2,
+			wantConfidence: 0.25,
+		},
+		{
+			name: "issue keys don't count as a different task",
+			history: stubHistory{
+				{Date: "2026-01-06", Repository: repo, Project: "Acme", Task: "ABC-1: Support", Hours: 1, Activity: 1},
+				{Date: "2026-01-07", Repository: repo, Project: "Acme", Task: "ABC-2: Support", Hours: 1, Activity: 1},
+			},
+			task:           DefaultTask,
+			wantProject:    "Acme",
+			wantTask:       "Support",
+			wantHours:      1,
+			wantConfidence: 0.5,
+		},
+		{
+			name: "a specific task only takes the same kind of work",
+			history: stubHistory{
+				{Date: "2026-01-06", Repository: repo, Project: "Acme", Task: "Support", Hours: 5, Activity: 1},
+			},
+			task:           "Bug Fix",
+			wantProject:    "Acme",
+			wantTask:       "Bug Fix",
+			wantHours:      1,
+			wantConfidence: 1.0 / 3,
+		},
+		{
+			name:  "a project a rule set is kept",
+			items: []activity.Item{{Kind: activity.KindCommit, Repository: repo, Project: "Internal", Title: "fix: login"}},
+			history: stubHistory{
+				{Date: "2026-01-06", Repository: repo, Project: "Acme", Task: "Bug Fix", Hours: 4, Activity: 1},
+			},
+			task:           "Bug Fix",
+			wantProject:    repo,
+			wantTask:       "Bug Fix",
+			wantHours:      1,
+			wantConfidence: 0,
+		},
+		{
+			name:           "learning off",
+			task:           "Bug Fix",
+			wantProject:    repo,
+			wantTask:       "Bug Fix",
+			wantHours:      1,
+			wantConfidence: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, err := NewTracker(nil, nil, Options{IssuePatterns: []string{`ABC-[0-9]+`}, History: tt.history})
+			if err != nil {
+				t.Fatal(err)
+			}
+			past, err := tr.loadHistory(time.Date(2026, 1, 13, 0, 0, 0, 0, time.Local))
+			if err != nil {
+				t.Fatalf("loadHistory: %v", err)
+			}
+
+			items := tt.items
+			if items == nil {
+				items = []activity.Item{commit}
+			}
+			entry := google.TimeEntry{Date: "2026-01-13", Project: repo, Task: tt.task, Hours: 1, GitCommits: "- fix: login"}
+			tr.prefill(&entry, items, past)
+
+			if entry.Project != tt.wantProject || entry.Task != tt.wantTask || entry.Hours != tt.wantHours {
+				t.Errorf("entry = %q/%q/%v, want %q/%q/%v", entry.Project, entry.Task, entry.Hours, tt.wantProject, tt.wantTask, tt.wantHours)
+			}
+			if diff := entry.Confidence - tt.wantConfidence; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("confidence = %v, want %v", entry.Confidence, tt.wantConfidence)
+			}
+			if entry.Repository != repo {
+				t.Errorf("repository = %q, want %q", entry.Repository, repo)
+			}
+		})
+	}
+}
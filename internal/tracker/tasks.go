@@ -0,0 +1,66 @@
+package tracker
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/digitaldrywood/timetracker/internal/activity"
+)
+
+// DefaultTask is the task of work without a recognised commit type.
+const DefaultTask = "Development"
+
+// DefaultTaskTypes maps Conventional Commit types to tasks.
+var DefaultTaskTypes = map[string]string{
+	"feat":     "Development",
+	"fix":      "Bug Fix",
+	"docs":     "Documentation",
+	"refactor": "Refactoring",
+	"perf":     "Performance",
+	"test":     "Testing",
+	"chore":    "Maintenance",
+	"build":    "Maintenance",
+	"ci":       "CI/CD",
+}
+
+// A Conventional Commit subject: "type(scope)!: description"
+var conventionalCommit = regexp.MustCompile(`^\s*([A-Za-z]+)(?:\([^)]*\))?!?:\s*`)
+
+// task returns the task an item's work belongs to: the one a rule set, or
+// the one for the Conventional Commit type of its title or the prefix of
+// its branch ("fix/login").
+func (t *Tracker) task(item activity.Item) string {
+	if item.Task != "" {
+		return item.Task
+	}
+	if match := conventionalCommit.FindStringSubmatch(item.Title); match != nil {
+		if task, ok := t.taskTypes[strings.ToLower(match[1])]; ok {
+			return task
+		}
+	}
+	if prefix, _, ok := strings.Cut(item.Branch, "/"); ok {
+		if task, ok := t.taskTypes[strings.ToLower(prefix)]; ok {
+			return task
+		}
+	}
+	return DefaultTask
+}
+
+// TaskType is the task_type recorded in the database for a task, e.g.
+// "bug_fix" for "Bug Fix".
+func TaskType(task string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(task) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			if underscore && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+			underscore = false
+			continue
+		}
+		underscore = true
+	}
+	return b.String()
+}
@@ -0,0 +1,56 @@
+package tracker
+
+import (
+	"testing"
+
+	"github.com/digitaldrywood/timetracker/internal/activity"
+)
+
+func TestTask(t *testing.T) {
+	tr, err := NewTracker(nil, nil, Options{TaskTypes: map[string]string{"Chore": "Support", "spike": "Research"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		item activity.Item
+		want string
+	}{
+		{"feature", activity.Item{Title: "feat: add export"}, "Development"},
+		{"fix", activity.Item{Title: "fix: handle expiry"}, "Bug Fix"},
+		{"scope and breaking change", activity.Item{Title: "refactor(auth)!: drop sessions"}, "Refactoring"},
+		{"type is case insensitive", activity.Item{Title: "Docs: update README"}, "Documentation"},
+		{"configured type", activity.Item{Title: "spike: try caching"}, "Research"},
+		{"configured type overrides the default", activity.Item{Title: "chore: bump deps"}, "Support"},
+		{"branch prefix", activity.Item{Title: "Handle expiry", Branch: "fix/login"}, "Bug Fix"},
+		{"title wins over branch", activity.Item{Title: "test: cover login", Branch: "fix/login"}, "Testing"},
+		{"task a rule set wins", activity.Item{Title: "fix: handle expiry", Task: "Support"}, "Support"},
+		{"unknown type", activity.Item{Title: "wip: tidy up", Branch: "main"}, DefaultTask},
+		{"no type", activity.Item{Title: "Update README"}, DefaultTask},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tr.task(tt.item); got != tt.want {
+				t.Errorf("task = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskType(t *testing.T) {
+	tests := []struct {
+		task string
+		want string
+	}{
+		{"Bug Fix", "bug_fix"},
+		{"CI/CD", "ci_cd"},
+		{"Development", "development"},
+		{"  Code  Review ", "code_review"},
+	}
+	for _, tt := range tests {
+		if got := TaskType(tt.task); got != tt.want {
+			t.Errorf("TaskType(%q) = %q, want %q", tt.task, got, tt.want)
+		}
+	}
+}
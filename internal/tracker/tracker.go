@@ -1,99 +1,263 @@
 package tracker
 
 import (
+	"context"
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/digitaldrywood/timetracker/internal/activity"
 	"github.com/digitaldrywood/timetracker/internal/github"
 	"github.com/digitaldrywood/timetracker/internal/google"
+	"github.com/digitaldrywood/timetracker/internal/rules"
 )
 
 type Tracker struct {
-	sheets *google.SheetsClient
-	github *github.Client
+	sheets  *google.SheetsClient
+	sources []activity.Source
+
+	issuePatterns []*regexp.Regexp
+	issueColumn   string
+	taskTypes     map[string]string
+	rules         *rules.Rules
+
+	descriptionTemplates map[string]string
+
+	history      History
+	historyWeeks int
+}
+
+// Options configures how suggestions are built.
+type Options struct {
+	// IssuePatterns are regular expressions matching issue keys such as
+	// "ABC-123" or "#456" in commit messages, branch names and PR titles.
+	// Work mentioning a key gets a suggestion of its own.
+	IssuePatterns []string
+
+	// IssueColumn is where the keys are written: IssueColumnTask (the
+	// default) or IssueColumnDescription
+	IssueColumn string
+
+	// TaskTypes maps Conventional Commit types ("feat", "fix") to tasks,
+	// on top of DefaultTaskTypes. Work is split into a suggestion per task.
+	TaskTypes map[string]string
+
+	// Rules classify every activity item before suggestions are built
+	Rules *rules.Rules
+
+	// DescriptionTemplates are text/templates for the Description column
+	// by task, on top of DefaultDescriptionTemplates. See
+	// rules.DescriptionData for what they can use.
+	DescriptionTemplates map[string]string
+
+	// History is where suggestions learn their project, task and hours
+	// from, looking back HistoryWeeks (DefaultHistoryWeeks when zero).
+	// Nil leaves suggestions as the activity has them.
+	History      History
+	HistoryWeeks int
 }
 
 type DailySummary struct {
 	Date             string
-	Commits          []github.Commit
-	PullRequests     []github.PullRequest
+	Items            []activity.Item
 	ExistingEntries  []google.TimeEntry
 	SuggestedEntries []google.TimeEntry
 }
 
-func NewTracker(sheets *google.SheetsClient, github *github.Client) *Tracker {
-	return &Tracker{
-		sheets: sheets,
-		github: github,
+func NewTracker(sheets *google.SheetsClient, sources []activity.Source, opts Options) (*Tracker, error) {
+	issuePatterns, err := compileIssuePatterns(opts.IssuePatterns)
+	if err != nil {
+		return nil, err
 	}
-}
 
-func (t *Tracker) GetDailySummary() (*DailySummary, error) {
-	today := time.Now().Format("2006-01-02")
+	switch opts.IssueColumn {
+	case "":
+		opts.IssueColumn = IssueColumnTask
+	case IssueColumnTask, IssueColumnDescription:
+	default:
+		return nil, fmt.Errorf("invalid issue column %q: must be %q or %q", opts.IssueColumn, IssueColumnTask, IssueColumnDescription)
+	}
 
-	commits, err := t.github.GetTodayCommits()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get commits: %v", err)
+	taskTypes := make(map[string]string)
+	for commitType, task := range DefaultTaskTypes {
+		taskTypes[commitType] = task
 	}
-	
-	// Also get local commits
-	localCommits, err := t.github.GetTodayLocalCommits()
-	if err == nil && len(localCommits) > 0 {
-		commits = append(commits, localCommits...)
+	for commitType, task := range opts.TaskTypes {
+		taskTypes[strings.ToLower(commitType)] = task
 	}
 
-	prs, err := t.github.GetTodayPullRequests()
+	descriptionTemplates := make(map[string]string)
+	for task, text := range DefaultDescriptionTemplates {
+		descriptionTemplates[task] = text
+	}
+	for task, text := range opts.DescriptionTemplates {
+		// Executing catches references to fields that don't exist
+		if _, err := rules.RenderDescription(text, rules.DescriptionData{}); err != nil {
+			return nil, fmt.Errorf("invalid description template for %q: %v", task, err)
+		}
+		descriptionTemplates[task] = text
+	}
+
+	if opts.HistoryWeeks == 0 {
+		opts.HistoryWeeks = DefaultHistoryWeeks
+	}
+	if opts.HistoryWeeks < 0 {
+		return nil, fmt.Errorf("invalid history weeks %d", opts.HistoryWeeks)
+	}
+
+	return &Tracker{
+		sheets:               sheets,
+		sources:              sources,
+		issuePatterns:        issuePatterns,
+		issueColumn:          opts.IssueColumn,
+		taskTypes:            taskTypes,
+		rules:                opts.Rules,
+		descriptionTemplates: descriptionTemplates,
+		history:              opts.History,
+		historyWeeks:         opts.HistoryWeeks,
+	}, nil
+}
+
+func (t *Tracker) GetDailySummary(ctx context.Context) (*DailySummary, error) {
+	today := time.Now()
+	return t.GetSummary(ctx, today, today)
+}
+
+// GetSummary collects activity and logged entries for every day from first
+// through last, and suggests entries for each day separately.
+func (t *Tracker) GetSummary(ctx context.Context, first, last time.Time) (*DailySummary, error) {
+	from, _ := github.DayRange(first)
+	_, to := github.DayRange(last)
+
+	label := from.Format("2006-01-02")
+	if lastDay := to.AddDate(0, 0, -1); lastDay.After(from) {
+		label += " to " + lastDay.Format("2006-01-02")
+	}
+
+	items, err := activity.FetchAll(ctx, t.sources, from, to)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get pull requests: %v", err)
+		return nil, fmt.Errorf("failed to get activity: %v", err)
 	}
+	items = t.rules.Apply(items)
 
-	existingEntries, err := t.sheets.GetTodayEntries()
+	existingEntries, err := t.sheets.GetEntries(from, to)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get existing entries: %v", err)
 	}
 
-	suggestedEntries := t.generateSuggestedEntries(commits, prs)
+	past, err := t.loadHistory(from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get past entries: %v", err)
+	}
+
+	byDay := make(map[string][]activity.Item)
+	for _, item := range items {
+		day := item.Time.In(from.Location()).Format("2006-01-02")
+		byDay[day] = append(byDay[day], item)
+	}
+
+	var suggestedEntries []google.TimeEntry
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		suggestedEntries = append(suggestedEntries, t.generateSuggestedEntries(byDay[date], date, past)...)
+	}
 
 	return &DailySummary{
-		Date:             today,
-		Commits:          commits,
-		PullRequests:     prs,
+		Date:             label,
+		Items:            items,
 		ExistingEntries:  existingEntries,
 		SuggestedEntries: suggestedEntries,
 	}, nil
 }
 
-func (t *Tracker) generateSuggestedEntries(commits []github.Commit, prs []github.PullRequest) []google.TimeEntry {
-	projectMap := make(map[string]*google.TimeEntry)
-	today := time.Now().Format("2006-01-02")
+// entryGroup identifies a suggestion: one kind of work in one project on
+// one issue, or on no issue in particular. Projects are repositories unless
+// a rule says otherwise.
+type entryGroup struct {
+	project  string
+	issue    string
+	task     string
+	client   string
+	billable bool
+}
 
-	for _, commit := range commits {
-		project := commit.Repository
-		if entry, exists := projectMap[project]; exists {
-			entry.GitCommits += fmt.Sprintf("\n- %s", strings.Split(commit.Message, "\n")[0])
+func (t *Tracker) generateSuggestedEntries(items []activity.Item, today string, past history) []google.TimeEntry {
+	projectMap := make(map[entryGroup]*google.TimeEntry)
+	itemsByGroup := make(map[entryGroup][]activity.Item)
+	workByGroup := make(map[entryGroup][]activity.Item)
+	workByProject := make(map[string][]activity.Item)
+	issueKeys := make(map[entryGroup][]string)
+
+	// groupOf is the group of the first issue key an item mentions
+	groupOf := func(item activity.Item) (entryGroup, []string) {
+		keys := t.issueKeys(item)
+		g := entryGroup{
+			project:  project(item),
+			task:     t.task(item),
+			client:   item.Client,
+			billable: billable(item),
+		}
+		if len(keys) > 0 {
+			g.issue = keys[0]
+		}
+		return g, keys
+	}
+	file := func(g entryGroup, keys []string, item activity.Item) {
+		for _, key := range keys {
+			if !slices.Contains(issueKeys[g], key) {
+				issueKeys[g] = append(issueKeys[g], key)
+			}
+		}
+		itemsByGroup[g] = append(itemsByGroup[g], item)
+	}
+	group := func(item activity.Item) entryGroup {
+		g, keys := groupOf(item)
+		file(g, keys, item)
+		return g
+	}
+	addWork := func(g entryGroup, item activity.Item) {
+		workByGroup[g] = append(workByGroup[g], item)
+		workByProject[g.project] = append(workByProject[g.project], item)
+	}
+
+	var commitGroups []entryGroup // in the order they were found
+	for _, commit := range activity.Filter(items, activity.KindCommit) {
+		g := group(commit)
+		addWork(g, commit)
+		if entry, exists := projectMap[g]; exists {
+			entry.GitCommits += fmt.Sprintf("\n- %s", commit.Title)
 		} else {
-			projectMap[project] = &google.TimeEntry{
+			commitGroups = append(commitGroups, g)
+			projectMap[g] = &google.TimeEntry{
 				Date:        today,
-				Project:     project,
-				Task:        "Development",
+				Project:     g.project,
+				Task:        g.task,
 				Hours:       0,
 				Description: "",
-				GitCommits:  fmt.Sprintf("- %s", strings.Split(commit.Message, "\n")[0]),
+				GitCommits:  fmt.Sprintf("- %s", commit.Title),
 				GitPRs:      "",
 			}
 		}
 	}
 
-	for _, pr := range prs {
-		project := pr.Repository
-		if entry, exists := projectMap[project]; exists {
+	// The user's own pull requests join the entry of the commits in their
+	// project, on the same issue if there is one; their titles don't
+	// always name the same kind of work as the commits do
+	for _, pr := range activity.Filter(items, activity.KindPullRequest) {
+		g, keys := groupOf(pr)
+		if _, exists := projectMap[g]; !exists {
+			g = attachGroup(commitGroups, g)
+		}
+		file(g, keys, pr)
+		if entry, exists := projectMap[g]; exists {
 			entry.GitPRs += fmt.Sprintf("\n- PR #%d: %s", pr.Number, pr.Title)
 		} else {
-			projectMap[project] = &google.TimeEntry{
+			projectMap[g] = &google.TimeEntry{
 				Date:        today,
-				Project:     project,
-				Task:        "Code Review",
+				Project:     g.project,
+				Task:        g.task,
 				Hours:       0,
 				Description: "",
 				GitCommits:  "",
@@ -102,11 +266,223 @@ func (t *Tracker) generateSuggestedEntries(commits []github.Commit, prs []github
 		}
 	}
 
+	// Uncommitted work counts towards the project's development time
+	for _, wip := range activity.Filter(items, activity.KindWorkInProgress) {
+		g := group(wip)
+		addWork(g, wip)
+		line := fmt.Sprintf("- Work in progress: %s (%s)", wip.Title, timeRange(wip))
+		if entry, exists := projectMap[g]; exists {
+			if entry.GitCommits != "" {
+				line = "\n" + line
+			}
+			entry.GitCommits += line
+			entry.Task = g.task
+		} else {
+			projectMap[g] = &google.TimeEntry{
+				Date:       today,
+				Project:    g.project,
+				Task:       g.task,
+				GitCommits: line,
+			}
+		}
+	}
+
+	// Sessions show when the work happened; they only add a line of their
+	// own for an issue, or a project, nothing else was found for
+	for _, session := range activity.Filter(items, activity.KindSession) {
+		g := group(session)
+		_, exists := projectMap[g]
+		if g.issue == "" {
+			for other := range projectMap {
+				exists = exists || other.project == g.project
+			}
+		}
+		addWork(g, session)
+		if !exists {
+			projectMap[g] = &google.TimeEntry{
+				Date:       today,
+				Project:    g.project,
+				Task:       g.task,
+				GitCommits: fmt.Sprintf("- %s (%s)", session.Title, timeRange(session)),
+			}
+		}
+	}
+
 	var entries []google.TimeEntry
-	for _, entry := range projectMap {
+	for g, entry := range projectMap {
+		entry.Client = g.client
+		entry.Billable = g.billable
+		entry.Hours = t.groupHours(g, projectMap, workByGroup, workByProject)
+		t.prefill(entry, itemsByGroup[g], past)
+		entry.TaskType = TaskType(entry.Task)
+		t.describe(entry, itemsByGroup[g], issueKeys[g])
+		t.writeIssueKeys(entry, issueKeys[g])
 		entries = append(entries, *entry)
 	}
 
+	entries = append(entries, t.generateReviewEntries(activity.Filter(items, activity.KindReview), today, past)...)
+	entries = append(entries, t.generateIssueEntries(activity.Filter(items, activity.KindIssue), today, past)...)
+	entries = append(entries, t.generateMeetingEntries(activity.Filter(items, activity.KindMeeting), today, past)...)
+
+	return entries
+}
+
+// groupHours estimates a group's hours. The project's work is estimated as
+// a whole and shared between its groups in proportion to their own
+// estimates, so splitting by issue doesn't count the padding twice.
+func (t *Tracker) groupHours(g entryGroup, groups map[entryGroup]*google.TimeEntry, workByGroup map[entryGroup][]activity.Item, workByProject map[string][]activity.Item) float64 {
+	work := workByGroup[g]
+	if len(work) == 0 {
+		return 0
+	}
+
+	total := estimateWorkHours(workByProject[g.project])
+	if len(work) == len(workByProject[g.project]) {
+		return total
+	}
+
+	var sum float64
+	for other := range groups {
+		if other.project == g.project && len(workByGroup[other]) > 0 {
+			sum += estimateWorkHours(workByGroup[other])
+		}
+	}
+	share := estimateWorkHours(work) / sum
+	return roundQuarterHour(time.Duration(total * share * float64(time.Hour)))
+}
+
+// attachGroup picks the commit group a pull request in group g belongs to:
+// the first on the same project and issue, then the first on the same
+// project. Without one g is kept.
+func attachGroup(commitGroups []entryGroup, g entryGroup) entryGroup {
+	for _, other := range commitGroups {
+		if other.project == g.project && other.issue == g.issue {
+			return other
+		}
+	}
+	for _, other := range commitGroups {
+		if other.project == g.project {
+			return other
+		}
+	}
+	return g
+}
+
+// writeIssueKeys records the issue keys an entry's work mentions in the
+// configured column, e.g. "ABC-123: Development".
+func (t *Tracker) writeIssueKeys(entry *google.TimeEntry, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	list := strings.Join(keys, ", ")
+	if t.issueColumn == IssueColumnDescription {
+		if entry.Description != "" {
+			list += "\n" + entry.Description
+		}
+		entry.Description = list
+		return
+	}
+	entry.Task = list + ": " + entry.Task
+}
+
+// generateReviewEntries builds one Code Review entry per project from
+// the reviews the user submitted, linking each reviewed PR once and
+// estimating hours from when the reviews and comments were left.
+func (t *Tracker) generateReviewEntries(reviews []activity.Item, date string, past history) []google.TimeEntry {
+	projects, byProject := groupByProject(reviews)
+
+	var entries []google.TimeEntry
+	for _, p := range projects {
+		var (
+			links      []string
+			timestamps []time.Time
+		)
+		seen := make(map[int]bool)
+		for _, review := range byProject[p] {
+			timestamps = append(timestamps, review.Time)
+			if seen[review.Number] {
+				continue
+			}
+			seen[review.Number] = true
+			links = append(links, fmt.Sprintf("- Reviewed PR #%d: %s (%s)", review.Number, review.Title, review.URL))
+		}
+
+		entry := google.TimeEntry{
+			Date:    date,
+			Project: p,
+			Task:    "Code Review",
+			Hours:   estimateHours(timestamps),
+			GitPRs:  strings.Join(links, "\n"),
+		}
+		t.classify(&entry, byProject[p], past)
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// generateIssueEntries builds one Issue Triage entry per project from
+// the user's issue activity, listing each issue once with what was done to
+// it.
+func (t *Tracker) generateIssueEntries(issues []activity.Item, date string, past history) []google.TimeEntry {
+	projects, byProject := groupByProject(issues)
+
+	var entries []google.TimeEntry
+	for _, p := range projects {
+		var (
+			numbers    []int
+			timestamps []time.Time
+		)
+		actions := make(map[int][]string)
+		titles := make(map[int]string)
+		for _, issue := range byProject[p] {
+			timestamps = append(timestamps, issue.Time)
+			if _, seen := actions[issue.Number]; !seen {
+				numbers = append(numbers, issue.Number)
+				titles[issue.Number] = issue.Title
+			}
+			if !slices.Contains(actions[issue.Number], issue.State) {
+				actions[issue.Number] = append(actions[issue.Number], issue.State)
+			}
+		}
+
+		var lines []string
+		for _, number := range numbers {
+			lines = append(lines, fmt.Sprintf("- Issue #%d (%s): %s", number, strings.Join(actions[number], ", "), titles[number]))
+		}
+
+		entry := google.TimeEntry{
+			Date:    date,
+			Project: p,
+			Task:    "Issue Triage",
+			Hours:   estimateHours(timestamps),
+			GitPRs:  strings.Join(lines, "\n"),
+		}
+		t.classify(&entry, byProject[p], past)
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// generateMeetingEntries builds one Meeting entry per project (the client
+// the calendar assigned, unless a rule says otherwise), with the hours the
+// meetings took; overlapping meetings count once.
+func (t *Tracker) generateMeetingEntries(meetings []activity.Item, date string, past history) []google.TimeEntry {
+	projects, byProject := groupByProject(meetings)
+
+	var entries []google.TimeEntry
+	for _, p := range projects {
+		entry := google.TimeEntry{
+			Date:    date,
+			Project: p,
+			Task:    "Meeting",
+			Hours:   meetingHours(byProject[p]),
+		}
+		t.classify(&entry, byProject[p], past)
+		entries = append(entries, entry)
+	}
+
 	return entries
 }
 
@@ -141,40 +517,109 @@ func (t *Tracker) FormatDailySummary(summary *DailySummary) string {
 		output.WriteString("\n")
 	}
 
-	if len(summary.Commits) > 0 {
-		output.WriteString("💻 Today's Commits:\n")
-		commitsByRepo := make(map[string][]github.Commit)
-		for _, commit := range summary.Commits {
+	if commits := activity.Filter(summary.Items, activity.KindCommit); len(commits) > 0 {
+		output.WriteString("💻 Commits:\n")
+		commitsByRepo := make(map[string][]activity.Item)
+		for _, commit := range commits {
 			commitsByRepo[commit.Repository] = append(commitsByRepo[commit.Repository], commit)
 		}
 
 		for repo, commits := range commitsByRepo {
-			output.WriteString(fmt.Sprintf("  %s:\n", repo))
+			output.WriteString(fmt.Sprintf("  %s%s:\n", repo, diffStats(commits)))
 			for _, commit := range commits {
-				message := strings.Split(commit.Message, "\n")[0]
+				message := commit.Title
 				if len(message) > 60 {
 					message = message[:57] + "..."
 				}
-				output.WriteString(fmt.Sprintf("    - %s\n", message))
+				output.WriteString(fmt.Sprintf("    - %s%s (+%d/-%d)\n", message, commitRef(commit), commit.Additions, commit.Deletions))
 			}
 		}
 		output.WriteString("\n")
 	}
 
-	if len(summary.PullRequests) > 0 {
+	if prs := activity.Filter(summary.Items, activity.KindPullRequest); len(prs) > 0 {
 		output.WriteString("🔄 Pull Requests:\n")
-		for _, pr := range summary.PullRequests {
+		for _, pr := range prs {
 			output.WriteString(fmt.Sprintf("  • %s PR #%d: %s [%s]\n",
 				pr.Repository, pr.Number, pr.Title, pr.State))
 		}
 		output.WriteString("\n")
 	}
 
+	if reviews := activity.Filter(summary.Items, activity.KindReview); len(reviews) > 0 {
+		output.WriteString("👀 Reviews:\n")
+		for _, review := range reviews {
+			output.WriteString(fmt.Sprintf("  • %s PR #%d: %s [%s at %s]\n",
+				review.Repository, review.Number, review.Title, review.State, review.Time.Local().Format("15:04")))
+		}
+		output.WriteString("\n")
+	}
+
+	if issues := activity.Filter(summary.Items, activity.KindIssue); len(issues) > 0 {
+		output.WriteString("📝 Issues:\n")
+		for _, issue := range issues {
+			output.WriteString(fmt.Sprintf("  • %s #%d: %s [%s at %s]\n",
+				issue.Repository, issue.Number, issue.Title, issue.State, issue.Time.Local().Format("15:04")))
+		}
+		output.WriteString("\n")
+	}
+
+	if wip := activity.Filter(summary.Items, activity.KindWorkInProgress); len(wip) > 0 {
+		output.WriteString("🚧 Work in Progress:\n")
+		for _, item := range wip {
+			output.WriteString(fmt.Sprintf("  • %s: %s%s [%s]\n",
+				item.Repository, item.Title, commitRef(item), timeRange(item)))
+		}
+		output.WriteString("\n")
+	}
+
+	if sessions := activity.Filter(summary.Items, activity.KindSession); len(sessions) > 0 {
+		output.WriteString("⏱️ Sessions:\n")
+		for _, session := range sessions {
+			output.WriteString(fmt.Sprintf("  • %s %s: %s", session.Repository, timeRange(session), session.Title))
+			if session.Number > 0 {
+				output.WriteString(fmt.Sprintf(" (%d branch switches)", session.Number))
+			}
+			output.WriteString("\n")
+		}
+		output.WriteString("\n")
+	}
+
+	if meetings := activity.Filter(summary.Items, activity.KindMeeting); len(meetings) > 0 {
+		output.WriteString("📅 Meetings:\n")
+		for _, meeting := range meetings {
+			output.WriteString(fmt.Sprintf("  • %s %s: %s", meeting.Repository, timeRange(meeting), meeting.Title))
+			if meeting.Body != "" {
+				output.WriteString(fmt.Sprintf(" (with %s)", meeting.Body))
+			}
+			output.WriteString("\n")
+		}
+		output.WriteString("\n")
+	}
+
 	if len(summary.SuggestedEntries) > 0 {
 		output.WriteString("💡 Suggested Time Entries:\n")
 		for i, entry := range summary.SuggestedEntries {
 			output.WriteString(fmt.Sprintf("%d. Project: %s\n", i+1, entry.Project))
+			if entry.Confidence > 0 {
+				output.WriteString(fmt.Sprintf("   Learned from past entries: %.0f%% confidence\n", entry.Confidence*100))
+			}
+			if entry.Date != summary.Date {
+				output.WriteString(fmt.Sprintf("   Date: %s\n", entry.Date))
+			}
+			if entry.Client != "" {
+				output.WriteString(fmt.Sprintf("   Client: %s\n", entry.Client))
+			}
 			output.WriteString(fmt.Sprintf("   Task: %s\n", entry.Task))
+			if !entry.Billable {
+				output.WriteString("   Not billable\n")
+			}
+			if entry.Hours > 0 {
+				output.WriteString(fmt.Sprintf("   Estimated: %.2f hours\n", entry.Hours))
+			}
+			if entry.Description != "" {
+				output.WriteString(fmt.Sprintf("   Description:\n%s\n", indent(entry.Description, "     ")))
+			}
 			if entry.GitCommits != "" {
 				output.WriteString(fmt.Sprintf("   Commits:%s\n", entry.GitCommits))
 			}
@@ -187,3 +632,49 @@ func (t *Tracker) FormatDailySummary(summary *DailySummary) string {
 
 	return output.String()
 }
+
+// commitRef describes where a commit lives, e.g. " [feature/login, PR #12]".
+func commitRef(commit activity.Item) string {
+	var parts []string
+	if commit.Branch != "" {
+		parts = append(parts, commit.Branch)
+	}
+	if commit.Number > 0 {
+		parts = append(parts, fmt.Sprintf("PR #%d", commit.Number))
+	}
+	if commit.Kind == activity.KindCommit && !commit.Pushed {
+		parts = append(parts, "not pushed")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(parts, ", ") + "]"
+}
+
+// diffStats totals the changes in commits, e.g. " (+120/-30, 8 files)".
+func diffStats(commits []activity.Item) string {
+	var additions, deletions, files int
+	for _, commit := range commits {
+		additions += commit.Additions
+		deletions += commit.Deletions
+		files += commit.FilesChanged
+	}
+	if files == 0 {
+		return fmt.Sprintf(" (+%d/-%d)", additions, deletions)
+	}
+	return fmt.Sprintf(" (+%d/-%d, %d files)", additions, deletions, files)
+}
+
+// indent prefixes every line of s.
+func indent(s, prefix string) string {
+	return prefix + strings.ReplaceAll(s, "\n", "\n"+prefix)
+}
+
+// timeRange formats when an item happened, e.g. "09:15-11:40".
+func timeRange(item activity.Item) string {
+	start := item.Time.Local().Format("15:04")
+	if item.End.IsZero() || !item.End.After(item.Time) {
+		return start
+	}
+	return start + "-" + item.End.Local().Format("15:04")
+}
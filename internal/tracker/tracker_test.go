@@ -0,0 +1,51 @@
+package tracker
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/digitaldrywood/timetracker/internal/activity"
+)
+
+func TestAuthoredPullRequestsJoinCommits(t *testing.T) {
+	tr, err := NewTracker(nil, nil, Options{IssuePatterns: []string{`ABC-[0-9]+`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := time.Date(2026, 1, 13, 10, 0, 0, 0, time.Local)
+	items := []activity.Item{
+		{Kind: activity.KindCommit, Repository: "github.com/acme/website", Title: "fix: login", Time: at},
+		{Kind: activity.KindCommit, Repository: "github.com/acme/website", Title: "feat: ABC-1 export", Time: at.Add(time.Hour)},
+		{Kind: activity.KindPullRequest, Repository: "github.com/acme/website", Number: 7, Title: "Fix login", Time: at},
+		{Kind: activity.KindPullRequest, Repository: "github.com/acme/website", Number: 8, Title: "ABC-1: Export", Time: at},
+		{Kind: activity.KindPullRequest, Repository: "github.com/acme/docs", Number: 9, Title: "Update guide", Time: at},
+	}
+
+	entries := tr.generateSuggestedEntries(items, "2026-01-13", nil)
+
+	prs := make(map[string]string) // task to PR lines
+	for _, entry := range entries {
+		if entry.Task == "Code Review" {
+			t.Errorf("authored PRs made a Code Review entry: %+v", entry)
+		}
+		prs[entry.Project+" "+entry.Task] = entry.GitPRs
+	}
+	if len(entries) != 3 {
+		t.Fatalf("entries = %d, want 3: %+v", len(entries), entries)
+	}
+
+	tests := []struct {
+		entry, pr string
+	}{
+		{"github.com/acme/website Bug Fix", "PR #7"},
+		{"github.com/acme/website ABC-1: Development", "PR #8"},
+		{"github.com/acme/docs Development", "PR #9"},
+	}
+	for _, tt := range tests {
+		if !strings.Contains(prs[tt.entry], tt.pr) {
+			t.Errorf("%s PRs = %q, want %s", tt.entry, prs[tt.entry], tt.pr)
+		}
+	}
+}